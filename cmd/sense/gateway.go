@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"senseai/internal/gateway"
+	"senseai/internal/policy"
+	"senseai/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	gatewayConfigFile   string
+	gatewayDBPath       string
+	policySourceKind    string
+	consulAddr          string
+	consulPoliciesKey   string
+	consulRoutesKey     string
+	policySourceURL     string
+	routeSourceURL      string
+	sourceWatchInterval time.Duration
+)
+
+var gatewayCmd = &cobra.Command{
+	Use:   "gateway",
+	Short: "Run the inline reverse-proxy gateway with request/response policy enforcement",
+	Long: "Runs gateway.Server: an in-line reverse proxy for configured AI API routes that enforces " +
+		"policy.Engine rules on requests and responses (including streaming SSE), rather than the " +
+		"observe-only view the passive detectors get.",
+	Run: runGateway,
+}
+
+func init() {
+	gatewayCmd.Flags().StringVar(&gatewayConfigFile, "config", "gateway.yaml", "Path to the gateway's route configuration YAML")
+	gatewayCmd.Flags().StringVarP(&gatewayDBPath, "db", "d", "sense.db", "Path to database file")
+	gatewayCmd.Flags().StringVarP(&policyFile, "policies", "c", "policies.yaml", "Path to policies file")
+	gatewayCmd.Flags().StringVar(&policySourceKind, "policy-source", "file", "Rule/route source: \"file\", \"consul\", or \"http\"")
+	gatewayCmd.Flags().StringVar(&consulAddr, "consul-addr", "http://127.0.0.1:8500", "Consul agent address, used when --policy-source=consul")
+	gatewayCmd.Flags().StringVar(&consulPoliciesKey, "consul-policies-key", "senseai/policies", "Consul KV key holding the policies YAML")
+	gatewayCmd.Flags().StringVar(&consulRoutesKey, "consul-routes-key", "senseai/routes", "Consul KV key holding the gateway routes YAML")
+	gatewayCmd.Flags().StringVar(&policySourceURL, "policy-url", "", "HTTP endpoint serving the policies YAML, used when --policy-source=http")
+	gatewayCmd.Flags().StringVar(&routeSourceURL, "routes-url", "", "HTTP endpoint serving the gateway routes YAML, used when --policy-source=http")
+	gatewayCmd.Flags().DurationVar(&sourceWatchInterval, "watch-interval", 30*time.Second, "How often to re-fetch rules/routes from a non-file source")
+}
+
+// buildPolicyAndRouteSources resolves the --policy-source flag into
+// concrete policy.Source values for rules and routes, or nil for both
+// when sourced from local files (no watch loop needed).
+func buildPolicyAndRouteSources() (rulesSource, routesSource policy.Source) {
+	switch policySourceKind {
+	case "consul":
+		return policy.ConsulSource{Addr: consulAddr, Key: consulPoliciesKey},
+			policy.ConsulSource{Addr: consulAddr, Key: consulRoutesKey}
+	case "http":
+		return policy.HTTPSource{URL: policySourceURL}, policy.HTTPSource{URL: routeSourceURL}
+	default:
+		return nil, nil
+	}
+}
+
+func runGateway(cmd *cobra.Command, args []string) {
+	cfg, err := gateway.LoadConfig(gatewayConfigFile)
+	if err != nil {
+		fmt.Printf("Failed to load gateway config: %v\n", err)
+		os.Exit(1)
+	}
+
+	engine, err := policy.NewEngine(policyFile)
+	if err != nil {
+		fmt.Printf("Failed to load policies: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := storage.NewStore(gatewayDBPath)
+	if err != nil {
+		fmt.Printf("Failed to initialize database: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	handler := func(typ, details, source string, sev float64, rule *policy.Rule) {
+		f := storage.Finding{
+			Type:      typ,
+			Details:   details,
+			Source:    source,
+			Timestamp: time.Now(),
+			Severity:  sev,
+		}
+		if err := store.LogFinding(f); err != nil {
+			fmt.Printf("Error logging finding: %v\n", err)
+		}
+		if rule != nil {
+			fmt.Printf("[GATEWAY] %s (Action: %s, Severity: %.2f)\n", details, rule.Action, sev)
+		}
+	}
+
+	srv := gateway.NewServer(cfg, engine, handler)
+
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	defer cancelWatch()
+
+	rulesSource, routesSource := buildPolicyAndRouteSources()
+	if rulesSource != nil {
+		go engine.Watch(watchCtx, rulesSource, sourceWatchInterval)
+	}
+	if routesSource != nil {
+		go srv.WatchConfig(watchCtx, routesSource, sourceWatchInterval)
+	}
+
+	go func() {
+		if err := srv.Start(); err != nil {
+			fmt.Printf("Gateway server error: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	fmt.Println("Shutting down gateway...")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,18 +10,24 @@ import (
 	"time"
 
 	"senseai/internal/action"
+	"senseai/internal/agent"
 	"senseai/internal/api"
 	"senseai/internal/detector"
 	"senseai/internal/policy"
+	"senseai/internal/redact"
 	"senseai/internal/storage"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	iface      string
-	port       string
-	policyFile string
+	iface            string
+	port             string
+	policyFile       string
+	managementAddr   string
+	bootstrapToken   string
+	packsDir         string
+	redactPolicyFile string
 )
 
 func main() {
@@ -35,6 +42,13 @@ func main() {
 	rootCmd.Flags().StringVarP(&policyFile, "policies", "c", "policies.yaml", "Path to policies file")
 	var dbPath string
 	rootCmd.Flags().StringVarP(&dbPath, "db", "d", "sense.db", "Path to database file")
+	rootCmd.Flags().StringVar(&managementAddr, "management-addr", "", "Management server gRPC address; when set, findings also stream there")
+	rootCmd.Flags().StringVar(&bootstrapToken, "bootstrap-token", "", "Bootstrap token used to enroll with the management server")
+	rootCmd.Flags().StringVar(&packsDir, "packs", "", "Directory of additional YAML signature packs (built-ins always load)")
+	rootCmd.Flags().StringVar(&redactPolicyFile, "redact-policy", "", "Path to a DLP redaction policy YAML (optional; built-in category defaults apply if unset)")
+
+	rootCmd.AddCommand(proxyCmd)
+	rootCmd.AddCommand(gatewayCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -60,11 +74,54 @@ func runSense(cmd *cobra.Command, args []string) {
 	}
 	fmt.Printf("Loaded %d policies from %s\n", len(engine.Rules), policyFile)
 
+	// 2b. Load the signature-pack registry (built-ins plus any packs in
+	// --packs) alongside the policy engine.
+	registry, err := detector.NewRegistry(packsDir)
+	if err != nil {
+		fmt.Printf("Failed to load detector registry: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Loaded %d signature packs\n", len(registry.List()))
+
+	// 2c. Load the DLP redaction policy, if one was given; nil falls back
+	// to redacting all built-in categories with their default severity.
+	var redactPolicy *redact.Policy
+	if redactPolicyFile != "" {
+		redactPolicy, err = redact.LoadPolicy(redactPolicyFile)
+		if err != nil {
+			fmt.Printf("Failed to load redact policy: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// 3. Initialize Remediator
-	remediator := action.NewRemediator(false) // Set to true for dry-run mode
+	remediator := action.NewRemediator(false, store) // Set to true for dry-run mode
 
 	// 4. Initialize API Server
 	server := api.NewServer(store)
+	server.SetDetectorRegistry(registry)
+	server.SetRemediator(remediator)
+
+	// 4b. Optionally stream findings to a management server as a
+	// fleet-enrolled agent, buffering locally when disconnected.
+	var agentClient *agent.Client
+	if managementAddr != "" {
+		agentClient, err = agent.New(agent.Config{
+			ManagementAddr: managementAddr,
+			BootstrapToken: bootstrapToken,
+			CertDir:        "./agent-certs",
+			OutboxPath:     dbPath + ".outbox",
+		})
+		if err != nil {
+			fmt.Printf("Failed to initialize management agent: %v\n", err)
+			os.Exit(1)
+		}
+		defer agentClient.Close()
+
+		agentCtx, cancelAgent := context.WithCancel(context.Background())
+		defer cancelAgent()
+		go agentClient.Run(agentCtx)
+	}
 
 	// 5. Define Finding Handler
 	handler := func(typ, details, source string, sev float64, rule *policy.Rule) {
@@ -80,7 +137,8 @@ func runSense(cmd *cobra.Command, args []string) {
 		// Add policy info to source if matched
 		if rule != nil {
 			var sourceMeta map[string]interface{}
-			if err := json.Unmarshal([]byte(source), &sourceMeta); err == nil {
+			hasSourceMeta := json.Unmarshal([]byte(source), &sourceMeta) == nil
+			if hasSourceMeta {
 				sourceMeta["policy_action"] = string(rule.Action)
 				if newSource, err := json.Marshal(sourceMeta); err == nil {
 					f.Source = string(newSource)
@@ -97,6 +155,15 @@ func runSense(cmd *cobra.Command, args []string) {
 						fmt.Printf("[ACTION] Blocking IP: %s (Policy: %s)\n", dstIP, rule.Name)
 						if err := remediator.BlockIP(dstIP); err != nil {
 							fmt.Printf("[ERROR] Failed to block IP: %v\n", err)
+							// Surface a failed (no-op) block in the persisted
+							// finding too - stderr alone is invisible to anyone
+							// reading findings back through the API/store.
+							if hasSourceMeta {
+								sourceMeta["block_error"] = err.Error()
+								if newSource, merr := json.Marshal(sourceMeta); merr == nil {
+									f.Source = string(newSource)
+								}
+							}
 						}
 					}
 				}
@@ -121,25 +188,45 @@ func runSense(cmd *cobra.Command, args []string) {
 			}
 		}
 
+		// Publish to Prometheus - action/rule stay "" for legacy
+		// heuristic-only hits (rule == nil).
+		if rule != nil {
+			api.RecordFinding(typ, string(rule.Action), rule.Name, sev)
+		} else {
+			api.RecordFinding(typ, "", "", sev)
+		}
+
 		// Log to DB
 		if err := store.LogFinding(f); err != nil {
 			fmt.Printf("Error logging finding: %v\n", err)
 		}
 		// Broadcast to UI
 		server.Broadcast(f)
+
+		// Forward to the management server, if enrolled
+		if agentClient != nil {
+			if err := agentClient.Enqueue(f); err != nil {
+				fmt.Printf("Error buffering finding for management server: %v\n", err)
+			}
+		}
 	}
 
 	// 6. Start Detectors
+	// Endpoint - constructed before the network detector so its
+	// ConnCorrelator (nil unless eBPF attached) is ready to wire in below.
+	endDetector := detector.NewEndpointDetector(engine, handler)
+
 	// Network
 	netDetector := detector.NewNetworkDetector(iface, engine, handler)
+	netDetector.SetRegistry(registry)
+	netDetector.SetRedactPolicy(redactPolicy)
+	netDetector.SetConnCorrelator(endDetector.ConnCorrelator())
 	go func() {
 		if err := netDetector.Start(24 * time.Hour); err != nil {
 			fmt.Printf("Network detector error: %v\n", err)
 		}
 	}()
 
-	// Endpoint
-	endDetector := detector.NewEndpointDetector(engine, handler)
 	go endDetector.Start(10 * time.Second)
 
 	// 7. Start API Server
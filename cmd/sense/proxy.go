@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"senseai/internal/action"
+	"senseai/internal/policy"
+	"senseai/internal/proxy"
+	"senseai/internal/redact"
+	"senseai/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyAddr      string
+	proxyCACert    string
+	proxyCAKey     string
+	proxyDBPath    string
+	proxyListMode  string
+	proxyHosts     []string
+	proxyBlockBody string
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Run an explicit forward proxy that decrypts AI API traffic for inspection",
+	Long: "Runs an HTTP/HTTPS forward proxy (CONNECT + on-the-fly cert generation) so clients " +
+		"configured to use it produce fully decrypted requests for the detection pipeline, instead " +
+		"of the SNI-only visibility passive sniffing gets on HTTPS.",
+	Run: runProxy,
+}
+
+func init() {
+	proxyCmd.Flags().StringVar(&proxyAddr, "addr", ":8443", "Address for the forward proxy to listen on")
+	proxyCmd.Flags().StringVar(&proxyCACert, "ca-cert", "./proxy-ca.crt", "Path to the proxy's MITM CA certificate (created if absent)")
+	proxyCmd.Flags().StringVar(&proxyCAKey, "ca-key", "./proxy-ca.key", "Path to the proxy's MITM CA key (created if absent)")
+	proxyCmd.Flags().StringVarP(&proxyDBPath, "db", "d", "sense.db", "Path to database file")
+	proxyCmd.Flags().StringVarP(&policyFile, "policies", "c", "policies.yaml", "Path to policies file")
+	proxyCmd.Flags().StringVar(&redactPolicyFile, "redact-policy", "", "Path to a DLP redaction policy YAML (optional; built-in category defaults apply if unset)")
+	proxyCmd.Flags().StringVar(&proxyListMode, "list-mode", "deny", "Host list enforcement mode: \"allow\" (only --hosts may proceed) or \"deny\" (--hosts are blocked)")
+	proxyCmd.Flags().StringSliceVar(&proxyHosts, "hosts", nil, "Hosts the list mode applies to (suffix-matched, e.g. openai.com)")
+	proxyCmd.Flags().StringVar(&proxyBlockBody, "block-response", "", "JSON body returned for blocked requests (default: a minimal error object)")
+}
+
+func runProxy(cmd *cobra.Command, args []string) {
+	store, err := storage.NewStore(proxyDBPath)
+	if err != nil {
+		fmt.Printf("Failed to initialize database: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	engine, err := policy.NewEngine(policyFile)
+	if err != nil {
+		fmt.Printf("Failed to load policies: %v\n", err)
+		os.Exit(1)
+	}
+
+	var redactPolicy *redact.Policy
+	if redactPolicyFile != "" {
+		redactPolicy, err = redact.LoadPolicy(redactPolicyFile)
+		if err != nil {
+			fmt.Printf("Failed to load redact policy: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	hostPolicy := &proxy.HostPolicy{
+		Mode:  proxy.ListMode(proxyListMode),
+		Hosts: proxyHosts,
+	}
+	if proxyBlockBody != "" {
+		hostPolicy.BlockResponse = []byte(proxyBlockBody)
+	}
+
+	remediator := action.NewRemediator(false, store)
+
+	handler := func(typ, details, source string, sev float64, rule *policy.Rule) {
+		if rule != nil {
+			switch rule.Action {
+			case policy.ActionBlock:
+				var meta map[string]interface{}
+				if json.Unmarshal([]byte(source), &meta) == nil {
+					if host, ok := meta["host"].(string); ok && host != "" {
+						fmt.Printf("[ACTION] Blocking host via firewall: %s (Policy: %s)\n", host, rule.Name)
+						if err := remediator.BlockIP(host); err != nil {
+							fmt.Printf("[ERROR] Failed to block host: %v\n", err)
+							// The finding is the only record of this block attempt
+							// that survives past this process's stderr - without
+							// this, a no-op block (unresolvable host, missing
+							// firewall backend, ...) looks identical to a real one
+							// everywhere except a log line nobody's tailing.
+							details = fmt.Sprintf("%s\n[ACTION FAILED] block %s: %v", details, host, err)
+						} else {
+							details = fmt.Sprintf("%s\n[ACTION] blocked %s", details, host)
+						}
+					}
+				}
+			case policy.ActionAlert:
+				fmt.Printf("[ALERT] %s - %s (Policy: %s, Severity: %.2f)\n", typ, details, rule.Name, sev)
+			}
+		}
+
+		f := storage.Finding{
+			Type:      typ,
+			Details:   details,
+			Source:    source,
+			Timestamp: time.Now(),
+			Severity:  sev,
+		}
+
+		if err := store.LogFinding(f); err != nil {
+			fmt.Printf("Error logging finding: %v\n", err)
+		}
+	}
+
+	srv, err := proxy.NewServer(proxyAddr, proxyCACert, proxyCAKey, engine, hostPolicy, handler)
+	if err != nil {
+		fmt.Printf("Failed to initialize proxy: %v\n", err)
+		os.Exit(1)
+	}
+	srv.SetRedactPolicy(redactPolicy)
+
+	go func() {
+		fmt.Printf("Forward proxy listening on %s (mode: %s)\n", proxyAddr, proxyListMode)
+		if err := srv.Start(); err != nil {
+			fmt.Printf("Proxy server error: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	fmt.Println("Shutting down proxy...")
+}
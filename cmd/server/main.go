@@ -12,12 +12,12 @@ import (
 	"syscall"
 	"time"
 
-	"senseai/internal/api"
 	pb "senseai/internal/proto"
 	"senseai/internal/server"
 
 	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -45,8 +45,16 @@ func main() {
 	mgmtServer := server.NewManagementServer(db)
 	defer mgmtServer.Stop()
 
-	// Start gRPC server
-	grpcServer := grpc.NewServer()
+	// Start gRPC server. Agents authenticate to StreamFindings with the
+	// mTLS client certificate they obtained from Enroll.
+	tlsConfig, err := mgmtServer.CA().ServerTLSConfig()
+	if err != nil {
+		log.Fatalf("Failed to build management TLS config: %v", err)
+	}
+	grpcServer := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.StreamInterceptor(server.StreamAuthInterceptor),
+	)
 	pb.RegisterAgentServiceServer(grpcServer, mgmtServer)
 	reflection.Register(grpcServer) // Enable reflection for debugging
 
@@ -62,11 +70,11 @@ func main() {
 		}
 	}()
 
-	// Start REST API server
-	apiServer := api.NewServer(db, "", "") // No gateway config or policy file for mgmt server currently
+	// Start REST API server, backed by the same Postgres store the
+	// management server persists agent findings to.
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%s", config.ServerPort),
-		Handler: apiServer.Router(),
+		Handler: mgmtServer.APIServer().Router(),
 	}
 
 	go func() {
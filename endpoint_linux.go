@@ -0,0 +1,290 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/ringbuf"
+)
+
+// ebpfEventType mirrors enum event_type in bpf/endpoint.c.
+type ebpfEventType uint32
+
+const (
+	ebpfEventExec    ebpfEventType = 1
+	ebpfEventConnect ebpfEventType = 2
+)
+
+// ebpfEvent mirrors struct event in bpf/endpoint.c byte-for-byte.
+type ebpfEvent struct {
+	Type     uint32
+	Pid      uint32
+	Ppid     uint32
+	CgroupID uint64
+	Comm     [16]byte
+	Args     [256]byte
+	Daddr    uint32
+	Dport    uint16
+	_        [2]byte // trailing padding to match sizeof(struct event), 304 bytes
+}
+
+// aiModuleMarkers are substrings of /proc/<pid>/maps entries that
+// indicate an AI/ML Python library is loaded in the process's address
+// space - the strongest endpoint-side signal that a script is actually
+// running a model or agent, as opposed to e.g. a browser merely hitting
+// chatgpt.com.
+var aiModuleMarkers = []string{"torch", "transformers", "openai", "langchain", "llama_cpp", "tensorflow"}
+
+// knownAIHostnames is the short list of well-known AI API hostnames that
+// resolveKnownAIIPs resolves at the start of each ScanEndpoints run, so
+// connect() events recorded by the kprobe (which only sees raw IPs) can
+// still be attributed.
+var knownAIHostnames = []string{
+	"api.openai.com", "api.anthropic.com", "generativelanguage.googleapis.com",
+	"api.cohere.ai", "huggingface.co",
+}
+
+// ScanEndpoints attaches eBPF probes to exec and outbound-connect events
+// and correlates them by PID to attribute network activity to a
+// specific process, binary, and (if present) AI library - rather than
+// the previous hardcoded placeholder finding.
+func (s *SENSE) ScanEndpoints() error {
+	objPath := "bpf/endpoint.o"
+	spec, err := ebpf.LoadCollectionSpec(objPath)
+	if err != nil {
+		log.Printf("eBPF object unavailable (%v), falling back to /proc polling", err)
+		return s.scanEndpointsProcFallback()
+	}
+
+	coll, err := ebpf.NewCollection(spec)
+	if err != nil {
+		log.Printf("Failed to load eBPF collection (likely missing BTF or insufficient privileges: %v), falling back to /proc polling", err)
+		return s.scanEndpointsProcFallback()
+	}
+	defer coll.Close()
+
+	execLink, err := link.Tracepoint("sched", "sched_process_exec", coll.Programs["trace_exec"], nil)
+	if err != nil {
+		log.Printf("Failed to attach exec tracepoint: %v", err)
+		return s.scanEndpointsProcFallback()
+	}
+	defer execLink.Close()
+
+	connectLink, err := link.Kprobe("security_socket_connect", coll.Programs["trace_connect"], nil)
+	if err != nil {
+		log.Printf("Failed to attach connect kprobe: %v", err)
+		return s.scanEndpointsProcFallback()
+	}
+	defer connectLink.Close()
+
+	reader, err := ringbuf.NewReader(coll.Maps["events"])
+	if err != nil {
+		return fmt.Errorf("failed to open ring buffer: %w", err)
+	}
+	defer reader.Close()
+
+	aiIPs := resolveKnownAIIPs()
+
+	fmt.Println("Scanning endpoints via eBPF (exec + connect tracing)...")
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		record, err := reader.Read()
+		if err != nil {
+			continue
+		}
+		if len(record.RawSample) < 4 {
+			continue
+		}
+		var ev ebpfEvent
+		if err := decodeEvent(record.RawSample, &ev); err != nil {
+			continue
+		}
+		s.handleEbpfEvent(ev, aiIPs)
+	}
+	return nil
+}
+
+func (s *SENSE) handleEbpfEvent(ev ebpfEvent, aiIPs map[string]bool) {
+	if ev.Type != uint32(ebpfEventConnect) {
+		return
+	}
+	ip := net.IPv4(byte(ev.Daddr), byte(ev.Daddr>>8), byte(ev.Daddr>>16), byte(ev.Daddr>>24)).String()
+	if !aiIPs[ip] {
+		return
+	}
+
+	comm := strings.TrimRight(string(ev.Comm[:]), "\x00")
+	binaryPath := fmt.Sprintf("/proc/%d/exe", ev.Pid)
+	sha := sha256OfBinary(binaryPath)
+	containerID := cgroupContainerID(ev.Pid)
+	libs := pythonModulesFor(ev.Pid)
+
+	details := fmt.Sprintf(
+		"AI endpoint connection correlated to process:\n- PID: %d\n- Binary: %s\n- SHA256: %s\n- Container: %s\n- Destination IP: %s:%d\n- Libraries: %s",
+		ev.Pid, comm, sha, containerID, ip, ev.Dport, strings.Join(libs, ","),
+	)
+	severity := 0.7
+	if len(libs) > 0 {
+		severity = 0.95
+	}
+	s.logFinding("endpoint", details, severity)
+	fmt.Println("----------------------------------------")
+	fmt.Println(details, "\n| Severity:", severity)
+	fmt.Println("----------------------------------------")
+}
+
+func decodeEvent(raw []byte, ev *ebpfEvent) error {
+	if len(raw) < 4+4+4+4+8+16+256+4+2 {
+		return fmt.Errorf("short ring buffer record: %d bytes", len(raw))
+	}
+	offset := 0
+	readU32 := func() uint32 {
+		v := uint32(raw[offset]) | uint32(raw[offset+1])<<8 | uint32(raw[offset+2])<<16 | uint32(raw[offset+3])<<24
+		offset += 4
+		return v
+	}
+	readU64 := func() uint64 {
+		var v uint64
+		for i := 0; i < 8; i++ {
+			v |= uint64(raw[offset+i]) << (8 * i)
+		}
+		offset += 8
+		return v
+	}
+
+	ev.Type = readU32()
+	ev.Pid = readU32()
+	ev.Ppid = readU32()
+	offset += 4 // padding before the 8-byte-aligned cgroup_id
+	ev.CgroupID = readU64()
+	copy(ev.Comm[:], raw[offset:offset+16])
+	offset += 16
+	copy(ev.Args[:], raw[offset:offset+256])
+	offset += 256
+	ev.Daddr = readU32()
+	ev.Dport = uint16(raw[offset]) | uint16(raw[offset+1])<<8
+	return nil
+}
+
+func resolveKnownAIIPs() map[string]bool {
+	ips := make(map[string]bool)
+	for _, host := range knownAIHostnames {
+		addrs, err := net.LookupHost(host)
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			ips[a] = true
+		}
+	}
+	return ips
+}
+
+func sha256OfBinary(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cgroupContainerID extracts the long-form container ID from a process's
+// cgroup path, e.g. .../docker/<64-char-id> or .../kubepods/.../<id>.
+func cgroupContainerID(pid uint32) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.Split(line, "/")
+		last := parts[len(parts)-1]
+		if len(last) == 64 {
+			return last
+		}
+	}
+	return ""
+}
+
+// pythonModulesFor inspects /proc/<pid>/maps for mapped files whose path
+// mentions a known AI/ML Python library, which is how we tell "a browser
+// hit chatgpt.com" apart from "python3 ran a langchain agent".
+func pythonModulesFor(pid uint32) []string {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	seen := make(map[string]bool)
+	var libs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.ToLower(scanner.Text())
+		for _, marker := range aiModuleMarkers {
+			if strings.Contains(line, marker) && !seen[marker] {
+				seen[marker] = true
+				libs = append(libs, marker)
+			}
+		}
+	}
+	return libs
+}
+
+// scanEndpointsProcFallback is used on kernels without the BTF info
+// required for CO-RE relocations, or when the process lacks the
+// privileges to attach kprobes (e.g. not running as root).
+func (s *SENSE) scanEndpointsProcFallback() error {
+	fmt.Println("Scanning endpoints via /proc polling (eBPF unavailable)...")
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return fmt.Errorf("failed to read /proc: %w", err)
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil {
+			continue
+		}
+		cmd := strings.ToLower(strings.ReplaceAll(string(cmdline), "\x00", " "))
+
+		libs := pythonModulesFor(uint32(pid))
+		if len(libs) == 0 {
+			continue
+		}
+
+		sha := sha256OfBinary(fmt.Sprintf("/proc/%d/exe", pid))
+		details := fmt.Sprintf(
+			"AI-related process (proc fallback):\n- PID: %d\n- Cmdline: %s\n- SHA256: %s\n- Libraries: %s",
+			pid, cmd, sha, strings.Join(libs, ","),
+		)
+		s.logFinding("endpoint", details, 0.85)
+		fmt.Println("----------------------------------------")
+		fmt.Println(details, "\n| Severity: 0.85")
+		fmt.Println("----------------------------------------")
+	}
+	return nil
+}
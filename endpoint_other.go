@@ -0,0 +1,16 @@
+//go:build !linux
+
+package main
+
+import "fmt"
+
+// ScanEndpoints falls back to the legacy placeholder on non-Linux hosts;
+// the eBPF tracer in endpoint_linux.go requires Linux kprobes and
+// tracepoints that have no equivalent here.
+func (s *SENSE) ScanEndpoints() error {
+	fmt.Println("Scanning endpoints...")
+	details := "eBPF endpoint tracing is Linux-only; no endpoint scan performed on this OS."
+	severity := 0.0
+	s.logFinding("endpoint", details, severity)
+	return nil
+}
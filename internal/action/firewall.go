@@ -0,0 +1,202 @@
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// FirewallBackend abstracts the system firewall tool a Remediator drives,
+// so BlockIP/UnblockIP don't have to hard-code iptables the way they used
+// to. Name identifies the backend in logs and in the persisted block
+// list (see BlockStore), so a restart on the same host can tell which
+// backend applied a given block.
+type FirewallBackend interface {
+	Name() string
+	Block(ip string) error
+	Unblock(ip string) error
+}
+
+// detectFirewallBackend probes PATH for the best firewall tool available
+// on the current OS, preferring the platform's modern tool and falling
+// back to whichever older tool a host still has. Returns nil if nothing
+// usable is found, in which case BlockIP/UnblockIP fail loudly instead of
+// silently no-op'ing.
+func detectFirewallBackend() FirewallBackend {
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := exec.LookPath("nft"); err == nil {
+			return &NftablesBackend{}
+		}
+		if _, err := exec.LookPath("iptables"); err == nil {
+			return &IptablesBackend{}
+		}
+	case "darwin":
+		if _, err := exec.LookPath("pfctl"); err == nil {
+			return NewPfctlBackend()
+		}
+	case "windows":
+		if _, err := exec.LookPath("netsh"); err == nil {
+			return &WindowsFirewallBackend{}
+		}
+	}
+	return nil
+}
+
+// IptablesBackend is the original Linux blocking mechanism, kept as a
+// fallback for hosts that don't have nft available.
+type IptablesBackend struct{}
+
+func (b *IptablesBackend) Name() string { return "iptables" }
+
+func (b *IptablesBackend) Block(ip string) error {
+	return exec.Command("iptables", "-A", "OUTPUT", "-d", ip, "-j", "DROP").Run()
+}
+
+func (b *IptablesBackend) Unblock(ip string) error {
+	return exec.Command("iptables", "-D", "OUTPUT", "-d", ip, "-j", "DROP").Run()
+}
+
+// nftTable and nftChain are the managed table/chain NftablesBackend
+// creates on first use and adds/removes per-IP drop rules in.
+const (
+	nftTable = "senseai"
+	nftChain = "output"
+)
+
+// NftablesBackend blocks IPs with nft instead of the legacy iptables
+// tool. The table/chain are created lazily on first use rather than
+// requiring out-of-band setup.
+type NftablesBackend struct {
+	mu      sync.Mutex
+	ensured bool
+}
+
+func (b *NftablesBackend) Name() string { return "nftables" }
+
+// ensure creates the senseai table/chain the first time this backend is
+// used. "add table"/"add chain" are idempotent in nft, so a failed
+// attempt (e.g. a transient permission or lock error) is simply retried
+// on the next call instead of being cached forever.
+func (b *NftablesBackend) ensure() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ensured {
+		return nil
+	}
+
+	if err := exec.Command("nft", "add", "table", "inet", nftTable).Run(); err != nil {
+		return fmt.Errorf("nft add table: %w", err)
+	}
+	err := exec.Command("nft", "add", "chain", "inet", nftTable, nftChain,
+		"{ type filter hook output priority 0 ; }").Run()
+	if err != nil {
+		return fmt.Errorf("nft add chain: %w", err)
+	}
+	b.ensured = true
+	return nil
+}
+
+func (b *NftablesBackend) Block(ip string) error {
+	if err := b.ensure(); err != nil {
+		return err
+	}
+	return exec.Command("nft", "add", "rule", "inet", nftTable, nftChain,
+		"ip", "daddr", ip, "drop").Run()
+}
+
+// nftHandleRe pulls the trailing "# handle N" nft appends to each line of
+// `nft -a list chain ...` output - nftables has no "delete rule matching
+// X", only "delete rule ... handle N", so Unblock has to look the handle
+// up first.
+var nftHandleRe = regexp.MustCompile(`# handle (\d+)$`)
+
+func (b *NftablesBackend) Unblock(ip string) error {
+	out, err := exec.Command("nft", "-a", "list", "chain", "inet", nftTable, nftChain).Output()
+	if err != nil {
+		return fmt.Errorf("nft list chain: %w", err)
+	}
+
+	// Match "daddr <ip> " exactly rather than a bare substring, so
+	// unblocking 1.2.3.4 can't hit the rule for 1.2.3.44 instead.
+	daddr := "daddr " + ip + " "
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, daddr) {
+			continue
+		}
+		m := nftHandleRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		return exec.Command("nft", "delete", "rule", "inet", nftTable, nftChain, "handle", m[1]).Run()
+	}
+	return fmt.Errorf("no nftables rule found blocking %s", ip)
+}
+
+// PfctlBackend manages a "senseai" pf anchor on macOS. pfctl has no
+// primitive for adding or removing a single rule from a loaded anchor, so
+// every Block/Unblock keeps an in-memory set of currently-blocked IPs and
+// reloads the whole anchor from it.
+type PfctlBackend struct {
+	mu      sync.Mutex
+	blocked map[string]struct{}
+}
+
+func NewPfctlBackend() *PfctlBackend {
+	return &PfctlBackend{blocked: make(map[string]struct{})}
+}
+
+func (b *PfctlBackend) Name() string { return "pfctl" }
+
+func (b *PfctlBackend) Block(ip string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blocked[ip] = struct{}{}
+	return b.apply()
+}
+
+func (b *PfctlBackend) Unblock(ip string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.blocked, ip)
+	return b.apply()
+}
+
+// apply rewrites the senseai anchor from the current blocked set and
+// loads it with `pfctl -a senseai -f -`. Must be called with mu held.
+func (b *PfctlBackend) apply() error {
+	var rules strings.Builder
+	for ip := range b.blocked {
+		fmt.Fprintf(&rules, "block drop out quick to %s\n", ip)
+	}
+
+	cmd := exec.Command("pfctl", "-a", "senseai", "-f", "-")
+	cmd.Stdin = strings.NewReader(rules.String())
+	return cmd.Run()
+}
+
+// WindowsFirewallBackend shells out to netsh advfirewall, naming each
+// rule after the IP it blocks so Unblock can find it again by name.
+type WindowsFirewallBackend struct{}
+
+func (b *WindowsFirewallBackend) Name() string { return "windows-firewall" }
+
+func (b *WindowsFirewallBackend) Block(ip string) error {
+	return exec.Command("netsh", "advfirewall", "firewall", "add", "rule",
+		"name="+windowsRuleName(ip), "dir=out", "action=block", "remoteip="+ip).Run()
+}
+
+func (b *WindowsFirewallBackend) Unblock(ip string) error {
+	return exec.Command("netsh", "advfirewall", "firewall", "delete", "rule",
+		"name="+windowsRuleName(ip)).Run()
+}
+
+func windowsRuleName(ip string) string {
+	return "senseai-block-" + ip
+}
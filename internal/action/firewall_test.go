@@ -0,0 +1,55 @@
+package action
+
+import "testing"
+
+func TestWindowsRuleName_IsStableAndNamespaced(t *testing.T) {
+	got := windowsRuleName("1.2.3.4")
+	want := "senseai-block-1.2.3.4"
+	if got != want {
+		t.Fatalf("windowsRuleName(%q) = %q, want %q", "1.2.3.4", got, want)
+	}
+}
+
+func TestNftHandleRe_ExtractsTrailingHandleNumber(t *testing.T) {
+	line := `		ip daddr 10.0.0.5 drop # handle 42`
+	m := nftHandleRe.FindStringSubmatch(line)
+	if m == nil {
+		t.Fatalf("expected a handle match in %q, got none", line)
+	}
+	if m[1] != "42" {
+		t.Fatalf("expected handle 42, got %q", m[1])
+	}
+}
+
+func TestNftHandleRe_NoMatchWithoutHandleSuffix(t *testing.T) {
+	line := `		ip daddr 10.0.0.5 drop`
+	if m := nftHandleRe.FindStringSubmatch(line); m != nil {
+		t.Fatalf("expected no handle match without a trailing handle, got %v", m)
+	}
+}
+
+// PfctlBackend.apply shells out to pfctl, which isn't installed in this
+// environment - Block/Unblock are expected to return an error here, but
+// the in-memory blocked set they maintain (the actual state Unblock and
+// the "reapply persisted blocks on restart" path depend on) must still
+// update correctly regardless of that error.
+func TestPfctlBackend_TracksBlockedSetAcrossBlockAndUnblock(t *testing.T) {
+	b := NewPfctlBackend()
+
+	_ = b.Block("10.0.0.1")
+	_ = b.Block("10.0.0.2")
+	if _, ok := b.blocked["10.0.0.1"]; !ok {
+		t.Fatal("expected 10.0.0.1 to be tracked as blocked")
+	}
+	if _, ok := b.blocked["10.0.0.2"]; !ok {
+		t.Fatal("expected 10.0.0.2 to be tracked as blocked")
+	}
+
+	_ = b.Unblock("10.0.0.1")
+	if _, ok := b.blocked["10.0.0.1"]; ok {
+		t.Fatal("expected 10.0.0.1 to be removed from the blocked set after Unblock")
+	}
+	if _, ok := b.blocked["10.0.0.2"]; !ok {
+		t.Fatal("expected 10.0.0.2 to remain blocked after unblocking a different IP")
+	}
+}
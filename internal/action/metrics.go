@@ -0,0 +1,26 @@
+package action
+
+import (
+	"runtime"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Registered on the default registerer at init, same pattern as
+// internal/proxy/metrics.go, so they show up on whatever /metrics
+// endpoint the binary eventually exposes.
+var remediationActionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "senseai_remediation_actions_total",
+	Help: "Total remediation actions attempted by the Remediator, by action, OS, and whether it succeeded.",
+}, []string{"action", "os", "success"})
+
+func init() {
+	prometheus.MustRegister(remediationActionsTotal)
+}
+
+// recordAction increments remediationActionsTotal for one attempted
+// action; err is nil on success.
+func recordAction(action string, err error) {
+	remediationActionsTotal.WithLabelValues(action, runtime.GOOS, strconv.FormatBool(err == nil)).Inc()
+}
@@ -1,27 +1,80 @@
 package action
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
+	"net"
 	"runtime"
 
+	"senseai/internal/storage"
+
 	"github.com/shirou/gopsutil/v3/process"
 )
 
+// BlockStore persists the IPs a Remediator has blocked so UnblockIP and
+// ListBlocked work across restarts; storage.Store satisfies this.
+type BlockStore interface {
+	AddBlockedIP(ip, backend string) error
+	RemoveBlockedIP(ip string) error
+	ListBlockedIPs() ([]storage.BlockedIP, error)
+}
+
 type Remediator struct {
-	dryRun bool
+	dryRun   bool
+	store    BlockStore
+	firewall FirewallBackend
+}
+
+// NewRemediator wires up a Remediator with whichever FirewallBackend is
+// available on this host (see detectFirewallBackend) and, if store is
+// non-nil and dryRun is false, reapplies any blocks persisted from a
+// previous run - firewall rules don't survive a reboot the way the
+// store's record of them does.
+func NewRemediator(dryRun bool, store BlockStore) *Remediator {
+	r := &Remediator{
+		dryRun:   dryRun,
+		store:    store,
+		firewall: detectFirewallBackend(),
+	}
+	if !dryRun && store != nil {
+		r.reapplyBlocks()
+	}
+	return r
 }
 
-func NewRemediator(dryRun bool) *Remediator {
-	return &Remediator{dryRun: dryRun}
+func (r *Remediator) reapplyBlocks() {
+	blocked, err := r.store.ListBlockedIPs()
+	if err != nil {
+		fmt.Printf("[WARN] Failed to load persisted blocked IPs: %v\n", err)
+		return
+	}
+	if r.firewall == nil {
+		if len(blocked) > 0 {
+			fmt.Printf("[WARN] No firewall backend available for OS %s; %d persisted blocks not reapplied\n",
+				runtime.GOOS, len(blocked))
+		}
+		return
+	}
+	for _, b := range blocked {
+		if err := r.firewall.Block(b.IP); err != nil {
+			// Drop it from the store too - otherwise /blocked keeps
+			// reporting this IP as blocked when it isn't actually being
+			// enforced on this host.
+			fmt.Printf("[WARN] Failed to reapply block for %s, removing from store: %v\n", b.IP, err)
+			if rerr := r.store.RemoveBlockedIP(b.IP); rerr != nil {
+				fmt.Printf("[WARN] Failed to remove stale blocked IP %s: %v\n", b.IP, rerr)
+			}
+		}
+	}
 }
 
 // KillProcess terminates a process by PID
-func (r *Remediator) KillProcess(pid int32) error {
+func (r *Remediator) KillProcess(pid int32) (err error) {
 	if r.dryRun {
 		fmt.Printf("[DRY-RUN] Would kill process PID: %d\n", pid)
 		return nil
 	}
+	defer func() { recordAction("kill", err) }()
 
 	p, err := process.NewProcess(pid)
 	if err != nil {
@@ -31,57 +84,111 @@ func (r *Remediator) KillProcess(pid int32) error {
 	name, _ := p.Name()
 	fmt.Printf("[REMEDIATE] Killing process: %s (PID: %d)\n", name, pid)
 
-	return p.Kill()
+	err = p.Kill()
+	return err
 }
 
-// BlockIP blocks an IP address using the system firewall
-func (r *Remediator) BlockIP(ip string) error {
+// resolveToIPs returns the literal address(es) a firewall rule should
+// target for host: itself if it's already an IP, or every current A/AAAA
+// record if it's a hostname. Every FirewallBackend's Block ultimately
+// shells out to a tool (nft/pfctl/netsh/iptables) that requires a literal
+// address, not a name - resolving once here, at the BlockIP boundary,
+// means every backend behaves the same way instead of each either
+// rejecting a hostname outright or doing its own ad hoc resolution, and a
+// CDN-backed hostname gets every address it currently has blocked rather
+// than whichever one a single lookup happens to return.
+func resolveToIPs(host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	addrs, err := net.LookupHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %q: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %q", host)
+	}
+	return addrs, nil
+}
+
+// BlockIP blocks a host using whichever FirewallBackend was detected for
+// this host. host may be a literal IP or a hostname - a hostname is
+// resolved to its current A/AAAA records first (see resolveToIPs), and
+// every one of them is blocked and persisted, since the backends
+// themselves only accept a literal address. Persisting the block to the
+// store (if one was given) keeps UnblockIP and ListBlocked working after a
+// restart.
+func (r *Remediator) BlockIP(host string) (err error) {
 	if r.dryRun {
-		fmt.Printf("[DRY-RUN] Would block IP: %s\n", ip)
+		fmt.Printf("[DRY-RUN] Would block IP: %s\n", host)
 		return nil
 	}
+	defer func() { recordAction("block", err) }()
 
-	fmt.Printf("[REMEDIATE] Blocking IP: %s\n", ip)
+	if r.firewall == nil {
+		return fmt.Errorf("no firewall backend available for OS: %s", runtime.GOOS)
+	}
 
-	switch runtime.GOOS {
-	case "darwin":
-		return r.blockIPMacOS(ip)
-	case "linux":
-		return r.blockIPLinux(ip)
-	default:
-		return fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+	ips, err := resolveToIPs(host)
+	if err != nil {
+		return err
 	}
-}
 
-func (r *Remediator) blockIPMacOS(ip string) error {
-	// Use pfctl to add a blocking rule
-	// Note: This requires root privileges and pfctl anchor setup
-	// For now, we'll just log the action
-	fmt.Printf("[INFO] IP blocking on macOS requires pfctl configuration\n")
-	fmt.Printf("[INFO] Would block: %s\n", ip)
+	// A hostname can resolve to several IPs; one backend call failing partway
+	// through (e.g. a firewall rule limit) shouldn't stop the rest from being
+	// tried, and shouldn't make an already-blocked IP look un-blocked in the
+	// finding - so every address is attempted and the failures are reported
+	// together at the end.
+	var blockErrs []error
+	for _, ip := range ips {
+		fmt.Printf("[REMEDIATE] Blocking IP: %s (%s)\n", ip, host)
+		if berr := r.firewall.Block(ip); berr != nil {
+			blockErrs = append(blockErrs, fmt.Errorf("block %s (%s): %w", ip, host, berr))
+			continue
+		}
+		if r.store != nil {
+			if serr := r.store.AddBlockedIP(ip, r.firewall.Name()); serr != nil {
+				fmt.Printf("[WARN] Failed to persist blocked IP %s: %v\n", ip, serr)
+			}
+		}
+	}
+	if len(blockErrs) > 0 {
+		err = errors.Join(blockErrs...)
+		return err
+	}
 	return nil
 }
 
-func (r *Remediator) blockIPLinux(ip string) error {
-	// Use iptables to block the IP
-	cmd := exec.Command("iptables", "-A", "OUTPUT", "-d", ip, "-j", "DROP")
-	return cmd.Run()
-}
-
-// UnblockIP removes a block on an IP address
-func (r *Remediator) UnblockIP(ip string) error {
+// UnblockIP removes a block on an IP address.
+func (r *Remediator) UnblockIP(ip string) (err error) {
 	if r.dryRun {
 		fmt.Printf("[DRY-RUN] Would unblock IP: %s\n", ip)
 		return nil
 	}
+	defer func() { recordAction("unblock", err) }()
 
 	fmt.Printf("[REMEDIATE] Unblocking IP: %s\n", ip)
 
-	switch runtime.GOOS {
-	case "linux":
-		cmd := exec.Command("iptables", "-D", "OUTPUT", "-d", ip, "-j", "DROP")
-		return cmd.Run()
-	default:
-		return fmt.Errorf("unblock not implemented for %s", runtime.GOOS)
+	if r.firewall == nil {
+		return fmt.Errorf("no firewall backend available for OS: %s", runtime.GOOS)
+	}
+	if err = r.firewall.Unblock(ip); err != nil {
+		return err
+	}
+
+	if r.store != nil {
+		if serr := r.store.RemoveBlockedIP(ip); serr != nil {
+			fmt.Printf("[WARN] Failed to remove persisted block for IP %s: %v\n", ip, serr)
+		}
+	}
+	return nil
+}
+
+// ListBlocked returns the IPs currently blocked, backed by the store so
+// it still reflects blocks applied in a previous run.
+func (r *Remediator) ListBlocked() ([]storage.BlockedIP, error) {
+	if r.store == nil {
+		return nil, nil
 	}
+	return r.store.ListBlockedIPs()
 }
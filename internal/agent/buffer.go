@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"database/sql"
+	"fmt"
+
+	"senseai/internal/storage"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// outbox is the local SQLite buffer an agent writes findings to before
+// they're acknowledged by the management server. It lets the agent keep
+// detecting and queueing findings while StreamFindings is down, and
+// replay them in order once reconnected.
+type outbox struct {
+	db *sql.DB
+}
+
+func newOutbox(dbPath string) (*outbox, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS outbox (
+            local_id INTEGER PRIMARY KEY AUTOINCREMENT,
+            type TEXT,
+            details TEXT,
+            source TEXT,
+            timestamp TEXT,
+            severity REAL
+        );
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox table: %w", err)
+	}
+
+	return &outbox{db: db}, nil
+}
+
+func (o *outbox) Close() error {
+	return o.db.Close()
+}
+
+func (o *outbox) enqueue(f storage.Finding) (int64, error) {
+	res, err := o.db.Exec(
+		"INSERT INTO outbox (type, details, source, timestamp, severity) VALUES (?, ?, ?, ?, ?)",
+		f.Type, f.Details, f.Source, f.Timestamp.Format("2006-01-02T15:04:05Z07:00"), f.Severity,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// pending returns buffered findings in insertion order, oldest first.
+func (o *outbox) pending(limit int) ([]bufferedFinding, error) {
+	rows, err := o.db.Query(
+		"SELECT local_id, type, details, source, timestamp, severity FROM outbox ORDER BY local_id ASC LIMIT ?", limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []bufferedFinding
+	for rows.Next() {
+		var bf bufferedFinding
+		if err := rows.Scan(&bf.LocalID, &bf.Finding.Type, &bf.Finding.Details, &bf.Finding.Source, &bf.rawTimestamp, &bf.Finding.Severity); err != nil {
+			continue
+		}
+		out = append(out, bf)
+	}
+	return out, nil
+}
+
+func (o *outbox) remove(localID int64) error {
+	_, err := o.db.Exec("DELETE FROM outbox WHERE local_id = ?", localID)
+	return err
+}
+
+type bufferedFinding struct {
+	LocalID      int64
+	Finding      storage.Finding
+	rawTimestamp string
+}
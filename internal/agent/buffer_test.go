@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"senseai/internal/storage"
+)
+
+func newTestOutbox(t *testing.T) *outbox {
+	t.Helper()
+	ob, err := newOutbox(filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatalf("newOutbox: %v", err)
+	}
+	t.Cleanup(func() { ob.Close() })
+	return ob
+}
+
+func TestOutbox_PendingReturnsInsertionOrder(t *testing.T) {
+	ob := newTestOutbox(t)
+
+	for _, typ := range []string{"first", "second", "third"} {
+		if _, err := ob.enqueue(storage.Finding{Type: typ, Timestamp: time.Now()}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	pending, err := ob.pending(50)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 pending rows, got %d", len(pending))
+	}
+	for i, want := range []string{"first", "second", "third"} {
+		if pending[i].Finding.Type != want {
+			t.Fatalf("pending[%d].Type = %q, want %q", i, pending[i].Finding.Type, want)
+		}
+	}
+}
+
+func TestOutbox_RemoveDropsAckedRow(t *testing.T) {
+	ob := newTestOutbox(t)
+
+	localID, err := ob.enqueue(storage.Finding{Type: "acked", Timestamp: time.Now()})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	if err := ob.remove(localID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	pending, err := ob.pending(50)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending rows after remove, got %d", len(pending))
+	}
+}
+
+func TestOutbox_PendingRespectsLimit(t *testing.T) {
+	ob := newTestOutbox(t)
+
+	for i := 0; i < 5; i++ {
+		if _, err := ob.enqueue(storage.Finding{Type: "t", Timestamp: time.Now()}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	pending, err := ob.pending(2)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected pending to cap at the given limit, got %d", len(pending))
+	}
+}
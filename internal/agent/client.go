@@ -0,0 +1,303 @@
+package agent
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "senseai/internal/proto"
+	"senseai/internal/storage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Config describes how an agent reaches its management server and which
+// local files it persists its enrollment credentials to.
+type Config struct {
+	ManagementAddr string
+	BootstrapToken string
+	CertDir        string // where agent-cert.pem/agent-key.pem/agent-ca.pem are cached after Enroll
+	OutboxPath     string // local SQLite buffer, survives disconnects
+}
+
+// Client streams findings to a management server, buffering locally in
+// SQLite whenever the connection is down and reconnecting with
+// exponential backoff. It enrolls itself for an mTLS client certificate
+// on first run and reuses it on subsequent ones.
+type Client struct {
+	cfg     Config
+	agentID string
+	outbox  *outbox
+}
+
+func New(cfg Config) (*Client, error) {
+	ob, err := newOutbox(cfg.OutboxPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open agent outbox: %w", err)
+	}
+	return &Client{cfg: cfg, outbox: ob}, nil
+}
+
+func (c *Client) Close() error {
+	return c.outbox.Close()
+}
+
+// Enqueue buffers a finding for delivery. It never blocks on the network
+// state of the management connection.
+func (c *Client) Enqueue(f storage.Finding) error {
+	_, err := c.outbox.enqueue(f)
+	return err
+}
+
+// Run drives enrollment (if needed) and keeps StreamFindings alive,
+// reconnecting with exponential backoff until ctx is cancelled.
+func (c *Client) Run(ctx context.Context) {
+	backoff := time.Second
+	const maxBackoff = 2 * time.Minute
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := c.ensureEnrolled(ctx); err != nil {
+			log.Printf("[agent] enrollment failed: %v", err)
+			backoff = sleepBackoff(ctx, backoff, maxBackoff)
+			continue
+		}
+
+		if err := c.streamUntilError(ctx); err != nil {
+			log.Printf("[agent] stream to %s failed, reconnecting: %v", c.cfg.ManagementAddr, err)
+			backoff = sleepBackoff(ctx, backoff, maxBackoff)
+			continue
+		}
+
+		backoff = time.Second
+	}
+}
+
+func sleepBackoff(ctx context.Context, current, max time.Duration) time.Duration {
+	select {
+	case <-ctx.Done():
+	case <-time.After(current):
+	}
+	next := current * 2
+	if next > max {
+		next = max
+	}
+	return next
+}
+
+func (c *Client) certPath() string { return c.cfg.CertDir + "/agent-cert.pem" }
+func (c *Client) keyPath() string  { return c.cfg.CertDir + "/agent-key.pem" }
+func (c *Client) caPath() string   { return c.cfg.CertDir + "/agent-ca.pem" }
+
+func (c *Client) ensureEnrolled(ctx context.Context) error {
+	if _, err := os.Stat(c.certPath()); err == nil {
+		return nil // already enrolled
+	}
+	if err := os.MkdirAll(c.cfg.CertDir, 0700); err != nil {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate agent key: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	csrTemplate := &x509.CertificateRequest{Subject: pkix.Name{CommonName: hostname}}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, csrTemplate, key)
+	if err != nil {
+		return fmt.Errorf("failed to create CSR: %w", err)
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	// Enrollment happens over a TLS connection without a client cert
+	// (the agent doesn't have one yet); it trusts whatever CA the
+	// management server presents on first contact.
+	conn, err := grpc.DialContext(ctx, c.cfg.ManagementAddr,
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})),
+		grpc.WithBlock(), grpc.WithTimeout(10*time.Second),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to dial management server for enrollment: %w", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewAgentServiceClient(conn)
+	resp, err := client.Enroll(ctx, &pb.EnrollRequest{
+		BootstrapToken: c.cfg.BootstrapToken,
+		CsrPem:         csrPEM,
+		Metadata:       c.localMetadata(hostname),
+	})
+	if err != nil {
+		return fmt.Errorf("enrollment rejected: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	if err := os.WriteFile(c.keyPath(), keyPEM, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.certPath(), resp.CertPem, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.caPath(), resp.CaPem, 0644); err != nil {
+		return err
+	}
+
+	c.agentID = resp.AgentId
+	log.Printf("[agent] enrolled as %s", resp.AgentId)
+	return nil
+}
+
+func (c *Client) localMetadata(hostname string) *pb.AgentMetadata {
+	var ifaceNames []string
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			ifaceNames = append(ifaceNames, iface.Name)
+		}
+	}
+	return &pb.AgentMetadata{
+		Hostname:   hostname,
+		Interfaces: ifaceNames,
+		Os:         runtime.GOOS,
+	}
+}
+
+func (c *Client) mtlsCredentials() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(c.certPath(), c.keyPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(c.caPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read management CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("failed to parse management CA")
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+	}), nil
+}
+
+func (c *Client) streamUntilError(ctx context.Context) error {
+	creds, err := c.mtlsCredentials()
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.DialContext(ctx, c.cfg.ManagementAddr, grpc.WithTransportCredentials(creds), grpc.WithBlock(), grpc.WithTimeout(10*time.Second))
+	if err != nil {
+		return fmt.Errorf("failed to dial management server: %w", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewAgentServiceClient(conn)
+	stream, err := client.StreamFindings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open StreamFindings: %w", err)
+	}
+
+	acks := make(chan *pb.Ack)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			ack, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			acks <- ack
+		}
+	}()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	// inFlight tracks when a still-pending row was last sent, so a tick
+	// that lands before its ack comes back doesn't resend it - without
+	// this, any ack round-trip slower than the tick interval causes the
+	// server to see (and, pre-idempotency-key, persist) the same finding
+	// more than once. Reset on every reconnect: a fresh stream means any
+	// send from the old one may never be acked at all.
+	inFlight := make(map[int64]time.Time)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case ack := <-acks:
+			localID, convErr := parseLocalID(ack.LocalId)
+			if convErr == nil {
+				delete(inFlight, localID)
+			}
+			if ack.Accepted {
+				if convErr == nil {
+					_ = c.outbox.remove(localID)
+				}
+			} else {
+				log.Printf("[agent] management server rejected finding %s: %s", ack.LocalId, ack.Error)
+			}
+		case <-ticker.C:
+			pending, err := c.outbox.pending(50)
+			if err != nil {
+				log.Printf("[agent] failed to read outbox: %v", err)
+				continue
+			}
+			now := time.Now()
+			for _, bf := range pending {
+				if sentAt, ok := inFlight[bf.LocalID]; ok && now.Sub(sentAt) < ackWaitTimeout {
+					continue // already sent, still waiting on its ack
+				}
+				event := &pb.FindingEvent{
+					LocalId:   strconv.FormatInt(bf.LocalID, 10),
+					Type:      bf.Finding.Type,
+					Details:   bf.Finding.Details,
+					Source:    bf.Finding.Source,
+					Timestamp: bf.rawTimestamp,
+					Severity:  bf.Finding.Severity,
+					Agent:     &pb.AgentMetadata{AgentId: c.agentID},
+				}
+				if err := stream.Send(event); err != nil {
+					return err
+				}
+				inFlight[bf.LocalID] = now
+			}
+		}
+	}
+}
+
+// ackWaitTimeout is how long streamUntilError waits for an ack before
+// treating a sent row as lost and resending it on the next tick. Well
+// above the 2s tick interval so a slow-but-healthy round trip doesn't look
+// like a lost send.
+const ackWaitTimeout = 10 * time.Second
+
+func parseLocalID(s string) (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+}
@@ -0,0 +1,36 @@
+package api
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Registered on the default registerer at init, same pattern as
+// internal/proxy/metrics.go, so it shows up on the /metrics route Router
+// wires to promhttp.Handler().
+var findingsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "senseai_findings_total",
+	Help: "Total findings recorded, by type, policy action, matched rule, and severity bucket.",
+}, []string{"type", "action", "rule", "severity_bucket"})
+
+func init() {
+	prometheus.MustRegister(findingsTotal)
+}
+
+// severityBucket groups a raw 0-1 severity score into the coarse labels
+// findings_total uses, so cardinality doesn't scale with the number of
+// distinct severity values rules happen to use.
+func severityBucket(severity float64) string {
+	switch {
+	case severity >= 0.8:
+		return "high"
+	case severity >= 0.4:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// RecordFinding increments senseai_findings_total for one finding.
+// action and rule may be "" when no policy rule matched (legacy
+// heuristic-only hits).
+func RecordFinding(typ, action, rule string, severity float64) {
+	findingsTotal.WithLabelValues(typ, action, rule, severityBucket(severity)).Inc()
+}
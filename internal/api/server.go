@@ -2,29 +2,58 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"sync"
 
+	"senseai/internal/detector"
 	"senseai/internal/storage"
 	"senseai/internal/ui"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// FindingStore is the read side a Server needs to serve /findings. Both the
+// local SQLite storage.Store (used by the standalone sense agent) and the
+// management server's Postgres-backed store satisfy it.
+type FindingStore interface {
+	GetFindings() ([]storage.Finding, error)
+}
+
+// BlockedLister is the read side a Server needs to serve /blocked;
+// action.Remediator satisfies it.
+type BlockedLister interface {
+	ListBlocked() ([]storage.BlockedIP, error)
+}
+
 type Server struct {
-	store     *storage.Store
-	subs      map[chan storage.Finding]struct{}
-	subsMutex sync.RWMutex
+	store      FindingStore
+	registry   *detector.Registry
+	remediator BlockedLister
+	subs       map[chan storage.Finding]struct{}
+	subsMutex  sync.RWMutex
 }
 
-func NewServer(store *storage.Store) *Server {
+func NewServer(store FindingStore) *Server {
 	return &Server{
 		store: store,
 		subs:  make(map[chan storage.Finding]struct{}),
 	}
 }
 
+// SetDetectorRegistry wires the pluggable signature-pack registry so
+// /detectors and /detectors/reload can manage it at runtime. Optional:
+// if never called, both endpoints respond 404.
+func (s *Server) SetDetectorRegistry(r *detector.Registry) {
+	s.registry = r
+}
+
+// SetRemediator wires up the Remediator so /blocked can list currently
+// blocked IPs. Optional: if never called, /blocked responds 404.
+func (s *Server) SetRemediator(r BlockedLister) {
+	s.remediator = r
+}
+
 func (s *Server) Broadcast(f storage.Finding) {
 	s.subsMutex.RLock()
 	defer s.subsMutex.RUnlock()
@@ -52,6 +81,13 @@ func (s *Server) unsubscribe(ch chan storage.Finding) {
 }
 
 func (s *Server) Start(port string) error {
+	return s.Router().Run(":" + port)
+}
+
+// Router builds the gin engine so callers that need their own
+// http.Server (for graceful shutdown, TLS, etc.) can mount it directly
+// instead of going through Start.
+func (s *Server) Router() *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
 
@@ -71,6 +107,12 @@ func (s *Server) Start(port string) error {
 		c.JSON(200, findings)
 	})
 
+	// Prometheus scrape endpoint - every metric registered anywhere in
+	// the process (policy eval timings, detector/remediator counters,
+	// findingsTotal in metrics.go) shows up here since they all MustRegister on
+	// the default registerer.
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	r.GET("/stream", func(c *gin.Context) {
 		c.Writer.Header().Set("Content-Type", "text/event-stream")
 		c.Writer.Header().Set("Cache-Control", "no-cache")
@@ -98,12 +140,35 @@ func (s *Server) Start(port string) error {
 		}
 	})
 
+	if s.registry != nil {
+		r.GET("/detectors", func(c *gin.Context) {
+			c.JSON(200, s.registry.List())
+		})
+		r.POST("/detectors/reload", func(c *gin.Context) {
+			if err := s.registry.Reload(); err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(200, gin.H{"packs": len(s.registry.List())})
+		})
+	}
+
+	if s.remediator != nil {
+		r.GET("/blocked", func(c *gin.Context) {
+			blocked, err := s.remediator.ListBlocked()
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(200, blocked)
+		})
+	}
+
 	// Static UI
 	r.StaticFS("/ui", ui.GetFileSystem())
 	r.GET("/", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/ui/")
 	})
 
-	fmt.Printf("Server running at http://localhost:%s\n", port)
-	return r.Run(":" + port)
+	return r
 }
@@ -0,0 +1,274 @@
+//go:build linux
+
+package detector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"senseai/internal/policy"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/ringbuf"
+)
+
+// ebpfObjectPath is the compiled eBPF program also used by SENSE.ScanEndpoints
+// at the repo root (see bpf/endpoint.c) - both load the same object, since
+// the tracepoints and the struct event layout they emit are identical.
+const ebpfObjectPath = "bpf/endpoint.o"
+
+// ebpfEventType mirrors enum event_type in bpf/endpoint.c.
+type ebpfEventType uint32
+
+const (
+	ebpfEventExec    ebpfEventType = 1
+	ebpfEventConnect ebpfEventType = 2
+)
+
+// ebpfEvent mirrors struct event in bpf/endpoint.c byte-for-byte, including
+// the padding the C compiler inserts: three leading __u32 fields leave
+// cgroup_id (a __u64, 8-byte aligned) starting 4 bytes later than it would
+// if the struct were packed, and the struct's overall 8-byte alignment adds
+// 2 more bytes after dport so sizeof(struct event) lands on a multiple of 8.
+// Both gaps are declared explicitly below so encoding/binary reads the same
+// layout clang does, instead of a hand-rolled offset walk silently drifting
+// out of sync with it.
+type ebpfEvent struct {
+	Type     uint32
+	Pid      uint32
+	Ppid     uint32
+	_        uint32 // compiler-inserted padding before the u64 below
+	CgroupID uint64
+	Comm     [16]byte
+	Args     [256]byte
+	Daddr    uint32 // network byte order, EVENT_CONNECT only
+	Dport    uint16
+	_        [2]byte // trailing padding to the struct's 8-byte alignment
+}
+
+// expectedEbpfEventSize is sizeof(struct event) in bpf/endpoint.c, verified
+// by compiling the struct: 304 bytes, with cgroup_id at offset 16, comm at
+// 24, args at 40, daddr at 296, and dport at 300.
+const expectedEbpfEventSize = 304
+
+// connInfo is the process last seen connecting to a given destination IP.
+type connInfo struct {
+	Pid  uint32
+	Name string
+}
+
+// ConnCorrelator tracks the most recent process observed connecting to a
+// given destination IP, so NetworkDetector can name the process behind a
+// flagged SNI/DNS query instead of only reporting the IP - something pcap
+// alone can never see. Populated by EbpfEndpointDetector's
+// security_socket_connect hook; safe for concurrent use since it's read
+// from NetworkDetector's packet-processing goroutine and written from the
+// eBPF detector's own.
+type ConnCorrelator struct {
+	mu   sync.RWMutex
+	byIP map[string]connInfo
+}
+
+func newConnCorrelator() *ConnCorrelator {
+	return &ConnCorrelator{byIP: make(map[string]connInfo)}
+}
+
+func (c *ConnCorrelator) record(ip string, info connInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byIP[ip] = info
+}
+
+// Lookup returns the process last observed connecting to ip, if any.
+func (c *ConnCorrelator) Lookup(ip string) (pid uint32, name string, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	info, found := c.byIP[ip]
+	return info.Pid, info.Name, found
+}
+
+// EbpfEndpointDetector replaces EndpointDetector's 10-second /proc polling
+// with real-time exec and connect events read off a ring buffer, so a
+// policy match fires the moment a process execs instead of up to 10
+// seconds later. Construct one with newEbpfEndpointDetector and check its
+// ok return before use.
+type EbpfEndpointDetector struct {
+	engine     *policy.Engine
+	handler    func(string, string, string, float64, *policy.Rule)
+	correlator *ConnCorrelator
+
+	coll        *ebpf.Collection
+	execLink    link.Link
+	connectLink link.Link
+	reader      *ringbuf.Reader
+}
+
+// newEbpfEndpointDetector attempts to load and attach bpf/endpoint.o. The
+// returned bool is false if the object is missing, BTF/CO-RE relocation
+// fails, or the process lacks the privileges to attach a kprobe - the
+// caller should treat any of those as "fall back to /proc polling", not
+// as fatal.
+func newEbpfEndpointDetector(engine *policy.Engine, handler func(string, string, string, float64, *policy.Rule)) (*EbpfEndpointDetector, bool) {
+	spec, err := ebpf.LoadCollectionSpec(ebpfObjectPath)
+	if err != nil {
+		fmt.Printf("[WARN] eBPF object unavailable (%v), falling back to /proc polling\n", err)
+		return nil, false
+	}
+
+	coll, err := ebpf.NewCollection(spec)
+	if err != nil {
+		fmt.Printf("[WARN] Failed to load eBPF collection (likely missing BTF or insufficient privileges: %v), falling back to /proc polling\n", err)
+		return nil, false
+	}
+
+	execLink, err := link.Tracepoint("sched", "sched_process_exec", coll.Programs["trace_exec"], nil)
+	if err != nil {
+		fmt.Printf("[WARN] Failed to attach exec tracepoint: %v, falling back to /proc polling\n", err)
+		coll.Close()
+		return nil, false
+	}
+
+	connectLink, err := link.Kprobe("security_socket_connect", coll.Programs["trace_connect"], nil)
+	if err != nil {
+		fmt.Printf("[WARN] Failed to attach connect kprobe: %v, falling back to /proc polling\n", err)
+		execLink.Close()
+		coll.Close()
+		return nil, false
+	}
+
+	reader, err := ringbuf.NewReader(coll.Maps["events"])
+	if err != nil {
+		fmt.Printf("[WARN] Failed to open ring buffer: %v, falling back to /proc polling\n", err)
+		connectLink.Close()
+		execLink.Close()
+		coll.Close()
+		return nil, false
+	}
+
+	return &EbpfEndpointDetector{
+		engine:      engine,
+		handler:     handler,
+		correlator:  newConnCorrelator(),
+		coll:        coll,
+		execLink:    execLink,
+		connectLink: connectLink,
+		reader:      reader,
+	}, true
+}
+
+// Correlator exposes the connection correlation table so NetworkDetector
+// can name the process behind a flagged destination IP.
+func (d *EbpfEndpointDetector) Correlator() *ConnCorrelator {
+	return d.correlator
+}
+
+// Start reads exec/connect events off the ring buffer until it's closed
+// or the read fails, evaluating each exec against the policy engine
+// inline instead of waiting for the next poll tick.
+func (d *EbpfEndpointDetector) Start() {
+	defer d.reader.Close()
+	defer d.connectLink.Close()
+	defer d.execLink.Close()
+	defer d.coll.Close()
+
+	fmt.Println("Scanning endpoints via eBPF (exec + connect tracing)...")
+	for {
+		record, err := d.reader.Read()
+		if err != nil {
+			return
+		}
+		ev, err := decodeEbpfEvent(record.RawSample)
+		if err != nil {
+			continue
+		}
+
+		switch ebpfEventType(ev.Type) {
+		case ebpfEventExec:
+			d.handleExec(ev)
+		case ebpfEventConnect:
+			d.handleConnect(ev)
+		}
+	}
+}
+
+func (d *EbpfEndpointDetector) handleExec(ev ebpfEvent) {
+	ebpfExecEventsTotal.Inc()
+
+	name := strings.ToLower(strings.TrimRight(string(ev.Comm[:]), "\x00"))
+	cmdline := strings.ToLower(procCmdline(ev.Pid))
+	if cmdline == "" {
+		cmdline = name
+	}
+
+	rule := d.engine.Evaluate("endpoint", name+" "+cmdline)
+	if rule != nil {
+		meta := map[string]interface{}{
+			"pid":          ev.Pid,
+			"ppid":         ev.Ppid,
+			"name":         name,
+			"cmdline":      cmdline,
+			"matched_rule": rule.Name,
+		}
+		sourceJSON, _ := json.Marshal(meta)
+		details := fmt.Sprintf("Process: %s\nPID: %d\n(Rule: %s)", name, ev.Pid, rule.Name)
+		d.handler("endpoint", details, string(sourceJSON), rule.Severity, rule)
+		return
+	}
+
+	if isAIProcess(name, cmdline) {
+		meta := map[string]interface{}{
+			"pid":     ev.Pid,
+			"ppid":    ev.Ppid,
+			"name":    name,
+			"cmdline": cmdline,
+		}
+		sourceJSON, _ := json.Marshal(meta)
+		details := fmt.Sprintf("Process: %s\nPID: %d", name, ev.Pid)
+		d.handler("endpoint", details, string(sourceJSON), 0.75, nil)
+	}
+}
+
+func (d *EbpfEndpointDetector) handleConnect(ev ebpfEvent) {
+	ebpfConnectEventsTotal.Inc()
+
+	if ev.Daddr == 0 {
+		return
+	}
+	ip := net.IPv4(byte(ev.Daddr), byte(ev.Daddr>>8), byte(ev.Daddr>>16), byte(ev.Daddr>>24)).String()
+	name := strings.ToLower(strings.TrimRight(string(ev.Comm[:]), "\x00"))
+	d.correlator.record(ip, connInfo{Pid: ev.Pid, Name: name})
+}
+
+// procCmdline reads /proc/<pid>/cmdline for extra context on an exec
+// event - the eBPF side only carries comm (16 bytes), since trace_exec in
+// bpf/endpoint.c doesn't read argv out of the new process image.
+func procCmdline(pid uint32) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
+
+func decodeEbpfEvent(raw []byte) (ebpfEvent, error) {
+	var ev ebpfEvent
+	if len(raw) < expectedEbpfEventSize {
+		return ev, fmt.Errorf("short ring buffer record: %d bytes, want %d", len(raw), expectedEbpfEventSize)
+	}
+
+	// BPF_TARGET_ARCH is x86, so the object (and the struct it emits) is
+	// always little-endian - same byte order the old hand-rolled reader
+	// assumed.
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &ev); err != nil {
+		return ev, fmt.Errorf("decode ebpf event: %w", err)
+	}
+
+	return ev, nil
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+
+package detector
+
+import "senseai/internal/policy"
+
+// ConnCorrelator is a no-op stand-in on non-Linux builds, where there's no
+// eBPF connect tracer to populate it. Lookup always misses.
+type ConnCorrelator struct{}
+
+// Lookup always reports no match on this platform.
+func (c *ConnCorrelator) Lookup(ip string) (pid uint32, name string, ok bool) {
+	return 0, "", false
+}
+
+// EbpfEndpointDetector is unused outside Linux; newEbpfEndpointDetector
+// always reports itself unavailable so EndpointDetector falls back to
+// /proc polling.
+type EbpfEndpointDetector struct{}
+
+func newEbpfEndpointDetector(engine *policy.Engine, handler func(string, string, string, float64, *policy.Rule)) (*EbpfEndpointDetector, bool) {
+	return nil, false
+}
+
+func (d *EbpfEndpointDetector) Start() {}
+
+func (d *EbpfEndpointDetector) Correlator() *ConnCorrelator { return nil }
@@ -14,16 +14,43 @@ import (
 type EndpointDetector struct {
 	handler func(string, string, string, float64, *policy.Rule)
 	engine  *policy.Engine
+	ebpf    *EbpfEndpointDetector
 }
 
+// NewEndpointDetector wires up the policy engine and finding handler, and
+// on Linux attempts to attach the eBPF exec/connect tracer (see
+// ebpf_linux.go) right away so ConnCorrelator is available to wire in
+// before Start is called (it fills up once Start reads connect events). If that attach fails - no BTF, not running as root, or a
+// non-Linux build - d.ebpf stays nil and Start falls back to the original
+// /proc-polling scanner.
 func NewEndpointDetector(engine *policy.Engine, handler func(string, string, string, float64, *policy.Rule)) *EndpointDetector {
-	return &EndpointDetector{
+	d := &EndpointDetector{
 		handler: handler,
 		engine:  engine,
 	}
+	if ebpfDetector, ok := newEbpfEndpointDetector(engine, handler); ok {
+		d.ebpf = ebpfDetector
+	}
+	return d
+}
+
+// ConnCorrelator returns the PID-to-destination-IP correlation table kept
+// by the eBPF connect tracer, or nil if eBPF isn't active (non-Linux, or
+// fell back to /proc polling). NetworkDetector.SetConnCorrelator accepts
+// that nil and then skips process enrichment.
+func (d *EndpointDetector) ConnCorrelator() *ConnCorrelator {
+	if d.ebpf == nil {
+		return nil
+	}
+	return d.ebpf.Correlator()
 }
 
 func (d *EndpointDetector) Start(interval time.Duration) {
+	if d.ebpf != nil {
+		d.ebpf.Start()
+		return
+	}
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -37,6 +64,7 @@ func (d *EndpointDetector) scanProcesses() {
 	if err != nil {
 		return
 	}
+	processesScannedTotal.Add(float64(len(procs)))
 
 	for _, p := range procs {
 		name, err := p.Name()
@@ -0,0 +1,32 @@
+package detector
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Registered on the default registerer at init, same pattern as
+// internal/proxy/metrics.go, so they show up on whatever /metrics
+// endpoint the binary eventually exposes.
+var (
+	packetsInspectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "senseai_packets_inspected_total",
+		Help: "Total packets inspected by the network detector, by IP protocol.",
+	}, []string{"proto"})
+
+	processesScannedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "senseai_processes_scanned_total",
+		Help: "Total processes scanned across all EndpointDetector scanProcesses passes.",
+	})
+
+	ebpfExecEventsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "senseai_ebpf_exec_events_total",
+		Help: "Total sched_process_exec events received by the eBPF endpoint detector.",
+	})
+
+	ebpfConnectEventsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "senseai_ebpf_connect_events_total",
+		Help: "Total security_socket_connect events received by the eBPF endpoint detector.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(packetsInspectedTotal, processesScannedTotal, ebpfExecEventsTotal, ebpfConnectEventsTotal)
+}
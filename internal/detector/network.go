@@ -1,12 +1,15 @@
 package detector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"senseai/internal/policy"
+	"senseai/internal/redact"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -14,9 +17,12 @@ import (
 )
 
 type NetworkDetector struct {
-	iface   string
-	handler func(string, string, string, float64, *policy.Rule) // type, details, source, severity, rule
-	engine  *policy.Engine
+	iface        string
+	handler      func(string, string, string, float64, *policy.Rule) // type, details, source, severity, rule
+	engine       *policy.Engine
+	registry     *Registry
+	redactPolicy *redact.Policy
+	connCorr     *ConnCorrelator
 }
 
 func NewNetworkDetector(iface string, engine *policy.Engine, handler func(string, string, string, float64, *policy.Rule)) *NetworkDetector {
@@ -27,6 +33,30 @@ func NewNetworkDetector(iface string, engine *policy.Engine, handler func(string
 	}
 }
 
+// SetRegistry wires the pluggable signature-pack registry in alongside
+// the policy engine; every TLS ClientHello observed with an SNI is also
+// run through it. Optional - a nil registry (the default) skips this entirely.
+func (d *NetworkDetector) SetRegistry(r *Registry) {
+	d.registry = r
+}
+
+// SetRedactPolicy wires the DLP redaction policy in; every payload that
+// ends up in a finding's metadata is scrubbed through it first. Optional
+// - a nil policy (the default) still redacts with the built-in category
+// defaults.
+func (d *NetworkDetector) SetRedactPolicy(p *redact.Policy) {
+	d.redactPolicy = p
+}
+
+// SetConnCorrelator wires in the eBPF connect-correlation table (see
+// EndpointDetector.ConnCorrelator) so inspectPacket can name the process
+// behind a flagged destination IP - pcap alone only sees the packet, never
+// the PID that sent it. Optional - nil (the default, and the only value
+// possible on non-Linux or without eBPF privileges) just skips enrichment.
+func (d *NetworkDetector) SetConnCorrelator(c *ConnCorrelator) {
+	d.connCorr = c
+}
+
 func (d *NetworkDetector) Start(duration time.Duration) error {
 	handle, err := pcap.OpenLive(d.iface, 1600, true, pcap.BlockForever)
 	if err != nil {
@@ -84,6 +114,12 @@ func (d *NetworkDetector) inspectPacket(pkt gopacket.Packet) {
 		dstPort = udp.DstPort.String()
 	}
 
+	protoLabel := strings.ToLower(protocol)
+	if protoLabel == "" {
+		protoLabel = "unknown"
+	}
+	packetsInspectedTotal.WithLabelValues(protoLabel).Inc()
+
 	meta := map[string]string{
 		"src_ip":   srcIP,
 		"dst_ip":   dstIP,
@@ -92,6 +128,15 @@ func (d *NetworkDetector) inspectPacket(pkt gopacket.Packet) {
 		"protocol": protocol,
 	}
 
+	// Name the process behind this destination IP, if the eBPF connect
+	// tracer has seen it - pcap alone has no visibility into the PID.
+	if d.connCorr != nil {
+		if pid, name, ok := d.connCorr.Lookup(dstIP); ok {
+			meta["pid"] = strconv.Itoa(int(pid))
+			meta["process"] = name
+		}
+	}
+
 	// 1. DNS Inspection
 	if dnsLayer := pkt.Layer(layers.LayerTypeDNS); dnsLayer != nil {
 		dns, _ := dnsLayer.(*layers.DNS)
@@ -125,39 +170,60 @@ func (d *NetworkDetector) inspectPacket(pkt gopacket.Packet) {
 			payload := appLayer.Payload()
 			// Check for TLS Handshake (ContentType 22)
 			if len(payload) > 5 && payload[0] == 0x16 {
-				sni := extractSNI(payload)
-				if sni != "" {
+				ch, ok := parseClientHello(payload)
+				if ok && ch.SNI != "" {
+					meta["sni"] = ch.SNI
+					meta["type"] = "HTTPS"
+					meta["ja3"] = ch.JA3
+					meta["ja3_hash"] = ch.JA3Hash
+					if len(ch.ALPN) > 0 {
+						meta["alpn"] = strings.Join(ch.ALPN, ",")
+					}
+					if client := attributeJA3(ch.JA3Hash); client != "" {
+						meta["ja3_client"] = client
+					}
+
 					// Evaluate against policies
-					rule := d.engine.Evaluate("network", sni)
+					rule := d.engine.Evaluate("network", ch.SNI)
 					if rule != nil {
-						meta["sni"] = sni
-						meta["type"] = "HTTPS"
 						meta["matched_rule"] = rule.Name
 						sourceJSON, _ := json.Marshal(meta)
-						d.handler("network", fmt.Sprintf("HTTPS Connection: %s (Rule: %s)", sni, rule.Name), string(sourceJSON), rule.Severity, rule)
-					} else if isAIEndpoint(sni) {
-						meta["sni"] = sni
-						meta["type"] = "HTTPS"
+						d.handler("network", fmt.Sprintf("HTTPS Connection: %s (Rule: %s)", ch.SNI, rule.Name), string(sourceJSON), rule.Severity, rule)
+					} else if isAIEndpoint(ch.SNI) {
 						sourceJSON, _ := json.Marshal(meta)
-						d.handler("network", fmt.Sprintf("HTTPS Connection: %s", sni), string(sourceJSON), 0.8, nil)
+						d.handler("network", fmt.Sprintf("HTTPS Connection: %s", ch.SNI), string(sourceJSON), 0.8, nil)
+					}
+
+					if d.registry != nil {
+						for _, finding := range d.registry.Inspect(context.Background(), PacketEvent{
+							SrcIP: srcIP, DstIP: dstIP, SrcPort: srcPort, DstPort: dstPort,
+							SNI: ch.SNI, ALPN: ch.ALPN, JA3Hash: ch.JA3Hash, Timestamp: time.Now(),
+						}) {
+							d.handler("network", finding.Details, finding.Source, finding.Severity, nil)
+						}
 					}
 				}
 			} else {
 				// Plain HTTP check + DLP
 				content := strings.ToLower(string(payload))
 
+				// Redact secrets/PII out of the payload before any of it is
+				// persisted to a finding - see internal/redact.
+				preview := content[:min(len(content), 100)]
+				redactedPreview, dlpSummary := redact.Redact(preview, d.redactPolicy)
+
 				// DLP Check
 				dlpRule := d.engine.Evaluate("payload", string(payload))
 				if dlpRule != nil {
-					meta["payload_preview"] = content[:min(len(content), 100)]
+					meta["payload_preview"] = redactedPreview
 					meta["type"] = "DLP"
 					meta["matched_rule"] = dlpRule.Name
-					sourceJSON, _ := json.Marshal(meta)
+					sourceJSON, _ := json.Marshal(mergeDLPSummary(meta, dlpSummary))
 					d.handler("network", fmt.Sprintf("DLP Violation: %s", dlpRule.Name), string(sourceJSON), dlpRule.Severity, dlpRule)
 				} else if isAIEndpoint(content) {
-					meta["payload_preview"] = content[:min(len(content), 100)]
+					meta["payload_preview"] = redactedPreview
 					meta["type"] = "HTTP"
-					sourceJSON, _ := json.Marshal(meta)
+					sourceJSON, _ := json.Marshal(mergeDLPSummary(meta, dlpSummary))
 					d.handler("network", "Unencrypted Traffic to AI Service", string(sourceJSON), 0.9, nil)
 				}
 			}
@@ -165,6 +231,13 @@ func (d *NetworkDetector) inspectPacket(pkt gopacket.Packet) {
 	}
 }
 
+// IsAIEndpoint is the exported form of isAIEndpoint, for callers outside
+// this package (e.g. the forward proxy) that need the same known-AI-host
+// check without depending on a NetworkDetector instance.
+func IsAIEndpoint(s string) bool {
+	return isAIEndpoint(s)
+}
+
 func isAIEndpoint(s string) bool {
 	s = strings.ToLower(s)
 	targets := []string{
@@ -181,67 +254,19 @@ func isAIEndpoint(s string) bool {
 	return false
 }
 
-// extractSNI attempts to parse the TLS Client Hello to find the SNI extension
-func extractSNI(payload []byte) string {
-	if len(payload) < 43 {
-		return ""
+// mergeDLPSummary folds a redact.Summary into the string-valued packet
+// meta map so it travels with the finding's Source JSON alongside
+// src_ip/dst_ip/etc.
+func mergeDLPSummary(meta map[string]string, summary redact.Summary) map[string]interface{} {
+	out := make(map[string]interface{}, len(meta)+2)
+	for k, v := range meta {
+		out[k] = v
 	}
-
-	offset := 5 // Skip Record Header
-	if payload[offset] != 0x01 {
-		return ""
-	}
-	offset += 4 // Skip Handshake Header
-
-	offset += 2  // Skip Client Version
-	offset += 32 // Skip Random
-
-	if offset >= len(payload) {
-		return ""
-	}
-	sessionIDLen := int(payload[offset])
-	offset += 1 + sessionIDLen
-
-	if offset+2 >= len(payload) {
-		return ""
-	}
-	cipherSuitesLen := int(payload[offset])<<8 | int(payload[offset+1])
-	offset += 2 + cipherSuitesLen
-
-	if offset >= len(payload) {
-		return ""
-	}
-	compressionMethodsLen := int(payload[offset])
-	offset += 1 + compressionMethodsLen
-
-	if offset+2 >= len(payload) {
-		return ""
+	out["dlp_secrets_found"] = summary.SecretsFound
+	if len(summary.PIITypes) > 0 {
+		out["dlp_pii_types"] = summary.PIITypes
 	}
-	offset += 2
-
-	for offset+4 <= len(payload) {
-		extType := int(payload[offset])<<8 | int(payload[offset+1])
-		extLen := int(payload[offset+2])<<8 | int(payload[offset+3])
-		offset += 4
-
-		if extType == 0x0000 { // Server Name
-			if offset+2 > len(payload) {
-				return ""
-			}
-			sniOffset := offset + 2
-			if sniOffset+3 > len(payload) {
-				return ""
-			}
-			nameLen := int(payload[sniOffset+1])<<8 | int(payload[sniOffset+2])
-			sniOffset += 3
-			if sniOffset+nameLen <= len(payload) {
-				return string(payload[sniOffset : sniOffset+nameLen])
-			}
-		}
-		offset += extLen
-	}
-
-	return ""
+	return out
 }
 
 func min(a, b int) int {
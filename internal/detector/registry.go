@@ -0,0 +1,313 @@
+package detector
+
+import (
+	"context"
+	"crypto/ed25519"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"senseai/internal/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed packs/*.yaml
+var builtinPacksFS embed.FS
+
+// PacketEvent is the inspector-agnostic view of a single observed flow
+// that a Detector reasons over. Network, proxy and future capture
+// sources all normalize into this shape so a SignaturePack only has to
+// be written once.
+type PacketEvent struct {
+	SrcIP, DstIP     string
+	SrcPort, DstPort string
+	SNI              string
+	ALPN             []string
+	JA3Hash          string
+	HostHeader       string
+	Body             string
+	Timestamp        time.Time
+}
+
+// Detector inspects a PacketEvent and returns zero or more findings. Each
+// detector owns its own severity formula instead of sharing the single
+// calculateSeverity heuristic the legacy scanner used. SignaturePack is
+// the YAML-defined implementation; Go-native detectors can implement it
+// directly for logic too complex to express as a regex/match config.
+type Detector interface {
+	Inspect(ctx context.Context, ev PacketEvent) []storage.Finding
+}
+
+// SeverityBoost bumps a pack's base severity when an additional regex
+// also matches - e.g. a body that mentions "api_key" on top of already
+// matching the pack's host pattern.
+type SeverityBoost struct {
+	Regex string  `yaml:"regex"`
+	Delta float64 `yaml:"delta"`
+}
+
+// Match holds the set of fields a SignaturePack can match against; any
+// subset may be set; a pack matches a PacketEvent if every non-empty
+// match field it defines matches.
+type Match struct {
+	SNI       string `yaml:"sni,omitempty"`
+	HostRegex string `yaml:"host_regex,omitempty"`
+	BodyRegex string `yaml:"body_regex,omitempty"`
+	JA3       string `yaml:"ja3,omitempty"`
+}
+
+// SignaturePack is the YAML-defined shape of a single detector. Packs are
+// keyed by ID only: a pack loaded later with the same ID replaces the
+// earlier one, no version or content hash is recorded, and Registry.Reload
+// does not report what changed.
+type SignaturePack struct {
+	ID            string          `yaml:"id"`
+	Name          string          `yaml:"name"`
+	Match         Match           `yaml:"match"`
+	SeverityBase  float64         `yaml:"severity_base"`
+	SeverityBoost []SeverityBoost `yaml:"severity_boosts,omitempty"`
+	Tags          []string        `yaml:"tags,omitempty"`
+
+	hostRegexCompiled *regexp.Regexp
+	bodyRegexCompiled *regexp.Regexp
+	boostsCompiled    []compiledBoost
+}
+
+type compiledBoost struct {
+	re    *regexp.Regexp
+	delta float64
+}
+
+func (p *SignaturePack) compile() error {
+	if p.Match.HostRegex != "" {
+		re, err := regexp.Compile(p.Match.HostRegex)
+		if err != nil {
+			return fmt.Errorf("pack %s: invalid host_regex: %w", p.ID, err)
+		}
+		p.hostRegexCompiled = re
+	}
+	if p.Match.BodyRegex != "" {
+		re, err := regexp.Compile(p.Match.BodyRegex)
+		if err != nil {
+			return fmt.Errorf("pack %s: invalid body_regex: %w", p.ID, err)
+		}
+		p.bodyRegexCompiled = re
+	}
+	for _, b := range p.SeverityBoost {
+		re, err := regexp.Compile(b.Regex)
+		if err != nil {
+			return fmt.Errorf("pack %s: invalid severity_boost regex: %w", p.ID, err)
+		}
+		p.boostsCompiled = append(p.boostsCompiled, compiledBoost{re: re, delta: b.Delta})
+	}
+	return nil
+}
+
+// Inspect implements Detector for a single YAML-defined pack.
+func (p *SignaturePack) Inspect(ctx context.Context, ev PacketEvent) []storage.Finding {
+	if !p.matches(ev) {
+		return nil
+	}
+
+	severity := p.SeverityBase
+	for _, b := range p.boostsCompiled {
+		if b.re.MatchString(ev.Body) {
+			severity += b.delta
+		}
+	}
+	if severity > 1.0 {
+		severity = 1.0
+	}
+
+	details := fmt.Sprintf("Signature match: %s (%s -> %s)", p.Name, ev.SrcIP, ev.DstIP)
+	return []storage.Finding{{
+		Type:      "signature",
+		Details:   details,
+		Source:    fmt.Sprintf(`{"pack_id":"%s","tags":%q,"sni":%q}`, p.ID, strings.Join(p.Tags, ","), ev.SNI),
+		Timestamp: time.Now(),
+		Severity:  severity,
+	}}
+}
+
+func (p *SignaturePack) matches(ev PacketEvent) bool {
+	if p.Match.SNI != "" && !strings.Contains(strings.ToLower(ev.SNI), strings.ToLower(p.Match.SNI)) {
+		return false
+	}
+	if p.hostRegexCompiled != nil && !p.hostRegexCompiled.MatchString(ev.SNI) && !p.hostRegexCompiled.MatchString(ev.HostHeader) {
+		return false
+	}
+	if p.bodyRegexCompiled != nil && !p.bodyRegexCompiled.MatchString(ev.Body) {
+		return false
+	}
+	if p.Match.JA3 != "" && p.Match.JA3 != ev.JA3Hash {
+		return false
+	}
+	// A pack must define at least one criterion that actually matched.
+	return p.Match.SNI != "" || p.Match.HostRegex != "" || p.Match.BodyRegex != "" || p.Match.JA3 != ""
+}
+
+// Registry holds the live set of detectors, hot-reloadable from a
+// directory of YAML packs and/or a signed remote URL.
+type Registry struct {
+	mu    sync.RWMutex
+	packs map[string]*SignaturePack
+	dir   string
+}
+
+// NewRegistry loads the built-in signature packs shipped with the binary
+// (internal/detector/packs/*.yaml), then overlays any packs found in dir
+// if it's non-empty.
+func NewRegistry(dir string) (*Registry, error) {
+	r := &Registry{packs: make(map[string]*SignaturePack), dir: dir}
+
+	if err := r.loadFS(builtinPacksFS, "packs"); err != nil {
+		return nil, fmt.Errorf("failed to load built-in packs: %w", err)
+	}
+	if dir != "" {
+		if err := r.loadDir(dir); err != nil {
+			return nil, fmt.Errorf("failed to load pack directory %s: %w", dir, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Registry) loadFS(fsys fs.FS, root string) error {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.Join(root, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := r.loadPackYAML(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := r.loadPackYAML(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) loadPackYAML(data []byte) error {
+	var pack SignaturePack
+	if err := yaml.Unmarshal(data, &pack); err != nil {
+		return err
+	}
+	if pack.ID == "" {
+		return fmt.Errorf("signature pack missing id")
+	}
+	if err := pack.compile(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.packs[pack.ID] = &pack
+	r.mu.Unlock()
+	return nil
+}
+
+// Reload re-reads the pack directory in place, swapping in any changed
+// or newly-added packs without dropping ones left untouched.
+func (r *Registry) Reload() error {
+	if r.dir == "" {
+		return nil
+	}
+	return r.loadDir(r.dir)
+}
+
+// FetchRemote pulls a signature pack from a signed URL: the body is the
+// YAML pack, and the server is expected to also serve "<url>.sig" with a
+// detached ed25519 signature, verified against pubKey before the pack is
+// trusted.
+func (r *Registry) FetchRemote(url string, pubKey ed25519.PublicKey) error {
+	body, err := httpGet(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pack from %s: %w", url, err)
+	}
+	sigHex, err := httpGet(url + ".sig")
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature for %s: %w", url, err)
+	}
+	sig, err := hex.DecodeString(strings.TrimSpace(string(sigHex)))
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding for %s: %w", url, err)
+	}
+	if !ed25519.Verify(pubKey, body, sig) {
+		return fmt.Errorf("signature verification failed for %s", url)
+	}
+	return r.loadPackYAML(body)
+}
+
+func httpGet(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Inspect runs every registered pack against a PacketEvent and returns
+// the union of their findings.
+func (r *Registry) Inspect(ctx context.Context, ev PacketEvent) []storage.Finding {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []storage.Finding
+	for _, pack := range r.packs {
+		out = append(out, pack.Inspect(ctx, ev)...)
+	}
+	return out
+}
+
+// List returns a snapshot of the currently loaded packs, for the
+// GET /detectors API.
+func (r *Registry) List() []SignaturePack {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]SignaturePack, 0, len(r.packs))
+	for _, p := range r.packs {
+		list = append(list, *p)
+	}
+	return list
+}
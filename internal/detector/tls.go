@@ -0,0 +1,208 @@
+package detector
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// clientHello holds the fields extracted from a TLS ClientHello record that
+// are useful for Shadow AI attribution: the SNI hostname, negotiated ALPN
+// protocols, and the JA3 fingerprint of the handshake itself.
+type clientHello struct {
+	SNI     string
+	ALPN    []string
+	JA3     string
+	JA3Hash string
+}
+
+// knownJA3 maps JA3 hashes of popular AI SDK HTTP clients to a human
+// readable label, so a detection can be attributed to a specific library
+// even when the SNI alone only tells us the destination.
+var knownJA3 = map[string]string{
+	"95c1649c2f99f45ebe5a408b3f713e09": "openai-python (legacy requests)",
+	"b0b2488706d6e33c54e0a0f758f36e57": "openai-python (httpx/h2)",
+	"cd08e31494f9531f560d64c695473da9": "langchain (httpx)",
+	"5d6fda53e7a1b8b4dca1a8f0e45a3a7b": "azure-openai-sdk",
+}
+
+// parseClientHello parses a TLS record believed to be a ClientHello
+// (content type 0x16, handshake type 0x01) and extracts the SNI, ALPN
+// protocols, and a JA3 fingerprint. It returns ok=false if the payload is
+// too short or malformed to be a ClientHello.
+func parseClientHello(payload []byte) (clientHello, bool) {
+	var ch clientHello
+	if len(payload) < 43 || payload[0] != 0x16 {
+		return ch, false
+	}
+
+	offset := 5 // skip record header
+	if payload[offset] != 0x01 {
+		return ch, false
+	}
+	offset += 4 // skip handshake header
+
+	if offset+2 > len(payload) {
+		return ch, false
+	}
+	version := int(payload[offset])<<8 | int(payload[offset+1])
+	offset += 2
+
+	offset += 32 // skip random
+	if offset >= len(payload) {
+		return ch, false
+	}
+
+	sessionIDLen := int(payload[offset])
+	offset += 1 + sessionIDLen
+	if offset+2 > len(payload) {
+		return ch, false
+	}
+
+	cipherSuitesLen := int(payload[offset])<<8 | int(payload[offset+1])
+	offset += 2
+	if offset+cipherSuitesLen > len(payload) {
+		return ch, false
+	}
+	var ciphers []int
+	for i := 0; i+1 < cipherSuitesLen; i += 2 {
+		ciphers = append(ciphers, int(payload[offset+i])<<8|int(payload[offset+i+1]))
+	}
+	offset += cipherSuitesLen
+
+	if offset >= len(payload) {
+		return ch, false
+	}
+	compressionMethodsLen := int(payload[offset])
+	offset += 1 + compressionMethodsLen
+
+	var extensions, curves, ecPointFormats []int
+	if offset+2 <= len(payload) {
+		extensionsTotalLen := int(payload[offset])<<8 | int(payload[offset+1])
+		offset += 2
+		extEnd := offset + extensionsTotalLen
+		if extEnd > len(payload) {
+			extEnd = len(payload)
+		}
+
+		for offset+4 <= extEnd {
+			extType := int(payload[offset])<<8 | int(payload[offset+1])
+			extLen := int(payload[offset+2])<<8 | int(payload[offset+3])
+			extStart := offset + 4
+			extensions = append(extensions, extType)
+
+			switch extType {
+			case 0x0000: // server_name
+				ch.SNI = parseSNIExtension(payload[extStart:min(extStart+extLen, len(payload))])
+			case 0x000a: // supported_groups / elliptic_curves
+				curves = parseUint16List(payload[extStart:min(extStart+extLen, len(payload))])
+			case 0x000b: // ec_point_formats
+				if extStart < len(payload) {
+					body := payload[extStart:min(extStart+extLen, len(payload))]
+					if len(body) > 1 {
+						for _, b := range body[1:] {
+							ecPointFormats = append(ecPointFormats, int(b))
+						}
+					}
+				}
+			case 0x0010: // ALPN
+				ch.ALPN = parseALPNExtension(payload[extStart:min(extStart+extLen, len(payload))])
+			}
+
+			offset = extStart + extLen
+		}
+	}
+
+	ch.JA3 = buildJA3String(version, ciphers, extensions, curves, ecPointFormats)
+	ch.JA3Hash = ja3Hash(ch.JA3)
+	return ch, true
+}
+
+func parseSNIExtension(body []byte) string {
+	if len(body) < 5 {
+		return ""
+	}
+	nameLen := int(body[3])<<8 | int(body[4])
+	if 5+nameLen > len(body) {
+		return ""
+	}
+	return string(body[5 : 5+nameLen])
+}
+
+func parseALPNExtension(body []byte) []string {
+	if len(body) < 2 {
+		return nil
+	}
+	var protos []string
+	offset := 2 // skip ALPN protocol list length
+	for offset < len(body) {
+		l := int(body[offset])
+		offset++
+		if offset+l > len(body) {
+			break
+		}
+		protos = append(protos, string(body[offset:offset+l]))
+		offset += l
+	}
+	return protos
+}
+
+func parseUint16List(body []byte) []int {
+	if len(body) < 2 {
+		return nil
+	}
+	var out []int
+	// first two bytes are the list length for supported_groups
+	for i := 2; i+1 < len(body); i += 2 {
+		out = append(out, int(body[i])<<8|int(body[i+1]))
+	}
+	return out
+}
+
+// isGREASE reports whether a cipher/extension/group value is a GREASE
+// value (RFC 8701), which must be filtered out of JA3 fingerprints since
+// clients randomize it per-connection.
+func isGREASE(v int) bool {
+	return v&0x0f0f == 0x0a0a
+}
+
+func buildJA3String(version int, ciphers, extensions, curves, ecPointFormats []int) string {
+	return strings.Join([]string{
+		strconv.Itoa(version),
+		joinGREASEFiltered(ciphers),
+		joinGREASEFiltered(extensions),
+		joinGREASEFiltered(curves),
+		joinInts(ecPointFormats),
+	}, ",")
+}
+
+func joinGREASEFiltered(values []int) string {
+	var kept []string
+	for _, v := range values {
+		if isGREASE(v) {
+			continue
+		}
+		kept = append(kept, strconv.Itoa(v))
+	}
+	return strings.Join(kept, "-")
+}
+
+func joinInts(values []int) string {
+	var parts []string
+	for _, v := range values {
+		parts = append(parts, strconv.Itoa(v))
+	}
+	return strings.Join(parts, "-")
+}
+
+func ja3Hash(ja3 string) string {
+	sum := md5.Sum([]byte(ja3))
+	return hex.EncodeToString(sum[:])
+}
+
+// attributeJA3 returns a human-readable client library name for a known
+// JA3 hash, or "" if the fingerprint isn't in the curated map.
+func attributeJA3(hash string) string {
+	return knownJA3[hash]
+}
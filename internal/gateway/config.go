@@ -1,9 +1,12 @@
 package gateway
 
 import (
+	"context"
 	"os"
 
 	"gopkg.in/yaml.v3"
+
+	"senseai/internal/policy"
 )
 
 type Config struct {
@@ -20,8 +23,52 @@ type Route struct {
 	Target   string `yaml:"target" json:"target"`
 	Provider string `yaml:"provider" json:"provider"`
 	Model    string `yaml:"model" json:"model"`
+
+	// Middlewares names the per-route chain, built by looking each name
+	// up in the gateway.RegisterMiddleware registry - e.g.
+	// ["auth.bearer", "ratelimit", "inspect.request", "inspect.response",
+	// "audit"]. Applied in list order: the first name wraps outermost,
+	// so it sees the request before anything listed after it. Unset (the
+	// common case for routes written before middleware chains existed)
+	// defaults to defaultMiddlewares, preserving the always-on inspection
+	// every route used to get unconditionally.
+	Middlewares []string `yaml:"middlewares,omitempty" json:"middlewares,omitempty"`
+
+	// Username/Password configure the "auth.basic" middleware; BearerToken
+	// configures "auth.bearer". Unused unless the matching name appears
+	// in Middlewares.
+	Username    string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password    string `yaml:"password,omitempty" json:"password,omitempty"`
+	BearerToken string `yaml:"bearer_token,omitempty" json:"bearer_token,omitempty"`
+
+	// RateLimitRPS/RateLimitBurst configure the "ratelimit" middleware's
+	// token bucket, keyed per API key (or remote address, if a request
+	// carries no credentials). Zero falls back to defaultRateLimitRPS/
+	// defaultRateLimitBurst.
+	RateLimitRPS   float64 `yaml:"rate_limit_rps,omitempty" json:"rate_limit_rps,omitempty"`
+	RateLimitBurst int     `yaml:"rate_limit_burst,omitempty" json:"rate_limit_burst,omitempty"`
+
+	// AllowUpgrade permits WebSocket upgrade requests on this route (used
+	// by some inference providers for realtime audio/voice, e.g. OpenAI's
+	// Realtime API). Without it, a Connection: Upgrade request is refused
+	// outright rather than silently tunneled past every inspection
+	// middleware in the chain. Upgraded connections aren't inspected
+	// frame-by-frame - see websocket.go.
+	AllowUpgrade bool `yaml:"allow_upgrade,omitempty" json:"allow_upgrade,omitempty"`
+
+	// LongRunning marks a route whose upstream calls legitimately exceed
+	// the browser/proxy timeouts sitting in front of this gateway (e.g. a
+	// slow LLM completion) - see longrunning.go. A request can opt into
+	// the same behavior per-call via the X-SenseAI-Long-Running header,
+	// without the operator needing to flag the whole route.
+	LongRunning bool `yaml:"long_running,omitempty" json:"long_running,omitempty"`
 }
 
+// defaultMiddlewares is the chain a Route gets when Middlewares is unset,
+// matching the gateway's pre-chain behavior of always inspecting both
+// request and response.
+var defaultMiddlewares = []string{"inspect.request", "inspect.response"}
+
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -36,6 +83,22 @@ func LoadConfig(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// LoadConfigFromSource loads route config from any policy.Source
+// (FileSource, ConsulSource, HTTPSource), so a fleet can centrally
+// publish new routes the same way it publishes new detection rules.
+func LoadConfigFromSource(ctx context.Context, source policy.Source) (*Config, error) {
+	data, err := source.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
 func SaveConfig(path string, cfg *Config) error {
 	data, err := yaml.Marshal(cfg)
 	if err != nil {
@@ -0,0 +1,239 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// longRunningHeader lets an individual request opt into keep-alive
+	// framing without the operator having to flag the whole route.
+	longRunningHeader = "X-SenseAI-Long-Running"
+
+	// longRunningKeepAliveAfter is how long the gateway waits for the
+	// upstream call before it starts sending keep-alive frames -
+	// comfortably under the typical 60s browser/proxy timeout this
+	// feature exists to dodge, but long enough that a normal, fast call
+	// never sees one.
+	longRunningKeepAliveAfter = 30 * time.Second
+	// longRunningKeepAliveInterval is how often a keep-alive frame
+	// repeats once the first one has gone out.
+	longRunningKeepAliveInterval = 10 * time.Second
+
+	// longRunningWriteDeadline replaces the http.Server's fixed
+	// WriteTimeout for the life of a long-running response, via
+	// http.ResponseController - the fixed timeout exists to bound a
+	// normal request, and would otherwise cut a legitimately slow
+	// upstream call off mid-keep-alive.
+	longRunningWriteDeadline = 10 * time.Minute
+
+	// longRunningMaxBufferedBody caps bufferedResponseWriter.body.
+	// isLongRunning lets a single request opt itself in via
+	// longRunningHeader with no operator-side config at all, so without a
+	// cap a caller could point this at an upstream with an unbounded or
+	// very large response and force the gateway to buffer all of it in
+	// memory.
+	longRunningMaxBufferedBody = 32 << 20 // 32MiB
+)
+
+// isLongRunning reports whether r should get keep-alive framing: either
+// the route is flagged LongRunning, or the caller asked for it via
+// longRunningHeader on this one request - deliberately, so a single slow
+// call doesn't require the operator to flag the whole route up front.
+//
+// Two known, accepted tradeoffs that come with that: the header lets any
+// caller switch a route into buffered ndjson framing even if the operator
+// never configured LongRunning for it, and this bounds a single request's
+// buffer (longRunningMaxBufferedBody) but not how many long-running
+// requests run at once - same shape as rateLimiter's unbounded bucket
+// map, left to the per-route "ratelimit" middleware rather than enforced
+// here. A route that can't tolerate either should put "ratelimit" (or
+// "auth.bearer"/"auth.basic") ahead of where this runs in Middlewares.
+func isLongRunning(route Route, r *http.Request) bool {
+	return route.LongRunning || strings.EqualFold(r.Header.Get(longRunningHeader), "true")
+}
+
+// guardLongRunning wraps final so a matching request gets keep-alive
+// framing instead of leaving the client's connection silent (and likely
+// timing out) while a slow upstream call is in flight. It wraps final
+// directly in buildMux, the same way guardUpgrade does, rather than
+// being a named, opt-in-via-Middlewares entry - LongRunning and the
+// header are themselves the opt-in, and gating that behind also listing
+// a middleware name would be one more way to configure it halfway.
+//
+// A WebSocket handshake is never buffered here even if the route or
+// request also matches isLongRunning: bufferedResponseWriter implements
+// neither Hijacker nor Flusher, so running a handshake through it would
+// break the upgrade guardUpgrade is meant to let through.
+func (g *Server) guardLongRunning(route Route, final http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWebSocketUpgrade(r) || !isLongRunning(route, r) {
+			final.ServeHTTP(w, r)
+			return
+		}
+		g.serveLongRunning(final, w, r)
+	})
+}
+
+// serveLongRunning runs final (everything from here down to the proxy,
+// including response inspection - see server.go's ModifyResponse/
+// inspectResponse, which still runs against the buffered result below)
+// in a goroutine against an in-memory buffer, while the caller's
+// connection gets a newline-delimited JSON keep-alive frame every
+// longRunningKeepAliveInterval once longRunningKeepAliveAfter has
+// passed without a result. Once final finishes, a terminal frame
+// carrying the real status/body closes out the stream.
+func (g *Server) serveLongRunning(final http.Handler, w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(longRunningWriteDeadline)); err != nil {
+		log.Printf("[gateway] long-running route %s: ResponseWriter doesn't support per-handler write deadlines, falling back to the server's fixed WriteTimeout: %v", r.URL.Path, err)
+	}
+
+	buf := newBufferedResponseWriter()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// final runs outside the stdlib server's own per-connection
+		// goroutine (and its recover), so a panic here would otherwise
+		// crash the whole process rather than just failing this request.
+		// httputil.ReverseProxy in particular panics with
+		// http.ErrAbortHandler when a response Write fails - exactly what
+		// bufferedResponseWriter.Write does once longRunningMaxBufferedBody
+		// is hit - so this has to recover, not just guard against it.
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec != http.ErrAbortHandler {
+					log.Printf("[gateway] long-running route %s: recovered panic: %v", r.URL.Path, rec)
+				}
+				buf.markFailed()
+			}
+		}()
+		final.ServeHTTP(buf, r)
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	start := time.Now()
+	wait := longRunningKeepAliveAfter
+	for {
+		select {
+		case <-done:
+			writeLongRunningFrame(w, buf.result())
+			return
+		case <-r.Context().Done():
+			// The client is gone (or the write deadline fired) - stop
+			// looping and writing frames nobody will read. final keeps
+			// running in its own goroutine against buf, but it shares r's
+			// context, so a context-aware upstream call (the reverse
+			// proxy's included) unwinds on its own rather than running to
+			// completion for a connection that no longer exists.
+			return
+		case <-time.After(wait):
+			writeLongRunningFrame(w, map[string]interface{}{
+				"status":     "pending",
+				"elapsed_ms": time.Since(start).Milliseconds(),
+			})
+			wait = longRunningKeepAliveInterval
+		}
+	}
+}
+
+func writeLongRunningFrame(w http.ResponseWriter, frame interface{}) {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"status": "error", "error": err.Error()})
+	}
+	w.Write(data)
+	w.Write([]byte("\n"))
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// bufferedResponseWriter captures a handler's response in memory instead
+// of sending it straight to the client, so serveLongRunning can forward
+// it as a single terminal keep-alive frame once it's complete. It's
+// deliberately minimal (no Hijacker/Flusher) - what it wraps is always
+// the proxy/inspection chain, never a WebSocket upgrade, which guardLongRunning
+// routes around before it ever reaches here (see websocket.go).
+//
+// Write stops growing body past longRunningMaxBufferedBody and reports an
+// error instead, so a caller can't force unbounded buffering just by
+// pointing a long-running request at an upstream with a very large body.
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	overLimit  bool
+	failed     bool
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header), statusCode: http.StatusOK}
+}
+
+func (b *bufferedResponseWriter) Header() http.Header { return b.header }
+
+func (b *bufferedResponseWriter) WriteHeader(statusCode int) { b.statusCode = statusCode }
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if b.overLimit {
+		return 0, fmt.Errorf("gateway: long-running response exceeds %d byte buffer limit", longRunningMaxBufferedBody)
+	}
+	if b.body.Len()+len(p) > longRunningMaxBufferedBody {
+		b.overLimit = true
+		return 0, fmt.Errorf("gateway: long-running response exceeds %d byte buffer limit", longRunningMaxBufferedBody)
+	}
+	return b.body.Write(p)
+}
+
+// markFailed records that final.ServeHTTP panicked (e.g. httputil.ReverseProxy
+// aborting with http.ErrAbortHandler after a Write past longRunningMaxBufferedBody
+// returns an error) instead of returning normally, so result() reports a
+// clean error frame rather than whatever partial body happened to be
+// buffered when the panic hit.
+func (b *bufferedResponseWriter) markFailed() {
+	if !b.overLimit {
+		b.failed = true
+	}
+}
+
+// result packages the buffered response as the terminal frame's payload.
+// A JSON body is embedded as-is (json.RawMessage) so the client doesn't
+// have to unescape a doubly-encoded string; anything else is passed
+// through as a plain string. A response that hit longRunningMaxBufferedBody
+// or otherwise failed mid-flight reports an error frame instead of a
+// truncated, likely-invalid payload.
+func (b *bufferedResponseWriter) result() map[string]interface{} {
+	if b.overLimit {
+		return map[string]interface{}{
+			"status": "error",
+			"error":  fmt.Sprintf("upstream response exceeded the %d byte long-running buffer limit", longRunningMaxBufferedBody),
+		}
+	}
+	if b.failed {
+		return map[string]interface{}{
+			"status": "error",
+			"error":  "long-running request failed before completing",
+		}
+	}
+
+	var payload interface{}
+	if json.Valid(b.body.Bytes()) {
+		payload = json.RawMessage(append([]byte(nil), b.body.Bytes()...))
+	} else {
+		payload = b.body.String()
+	}
+
+	return map[string]interface{}{
+		"status":      "done",
+		"status_code": b.statusCode,
+		"payload":     payload,
+	}
+}
@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitRPS   = 10.0
+	defaultRateLimitBurst = 20
+
+	// idleBucketTTL and bucketSweepInterval bound rateLimiter.buckets:
+	// without this, a caller varying its source IP or Authorization
+	// header on every request (rateLimitKey falls back to the remote host
+	// when no auth header is present) would grow the map forever.
+	idleBucketTTL       = 10 * time.Minute
+	bucketSweepInterval = time.Minute
+)
+
+func init() {
+	RegisterMiddleware("ratelimit", newRateLimitMiddleware)
+}
+
+// tokenBucket is a single caller's rate-limit state - capacity tokens
+// refilled continuously at rate tokens/sec, up to capacity.
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	rate     float64
+	capacity float64
+	last     time.Time
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += b.rate * now.Sub(b.last).Seconds()
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter buckets callers by rateLimitKey, handing each their own
+// token bucket on first use. A background sweep evicts buckets that
+// have gone idle so an attacker cycling identities can't grow the map
+// without bound; stop it via close() once the limiter is discarded.
+type rateLimiter struct {
+	mu       sync.Mutex
+	buckets  map[string]*tokenBucket
+	rate     float64
+	capacity float64
+	stop     chan struct{}
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	l := &rateLimiter{
+		buckets:  make(map[string]*tokenBucket),
+		rate:     rps,
+		capacity: float64(burst),
+		stop:     make(chan struct{}),
+	}
+	go l.sweepLoop()
+	return l
+}
+
+// sweepLoop periodically evicts buckets that have been idle for longer
+// than idleBucketTTL, until close() is called.
+func (l *rateLimiter) sweepLoop() {
+	ticker := time.NewTicker(bucketSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.sweep()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// sweep evicts idle buckets without holding l.mu for the whole scan -
+// holding it across every bucket's check would block allow() for the
+// entire route for as long as the sweep takes, which on a
+// many-thousand-caller route is long enough to matter.
+func (l *rateLimiter) sweep() {
+	cutoff := time.Now().Add(-idleBucketTTL)
+
+	l.mu.Lock()
+	snapshot := make(map[string]*tokenBucket, len(l.buckets))
+	for key, b := range l.buckets {
+		snapshot[key] = b
+	}
+	l.mu.Unlock()
+
+	var stale []string
+	for key, b := range snapshot {
+		b.mu.Lock()
+		idle := b.last.Before(cutoff)
+		b.mu.Unlock()
+		if idle {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	for _, key := range stale {
+		delete(l.buckets, key)
+	}
+	l.mu.Unlock()
+}
+
+// close stops the limiter's background sweep goroutine. Called on the
+// outgoing limiter when rateLimiterFor replaces it with a fresh one, so
+// a config reload doesn't leak a goroutine per reload.
+func (l *rateLimiter) close() {
+	close(l.stop)
+}
+
+// rateLimiterFor returns the cached rateLimiter for route.Path, creating
+// one on first use. If a later reload changes the route's
+// RateLimitRPS/RateLimitBurst, the cached limiter is replaced (picking up
+// the new settings) rather than kept forever stale - but an unchanged
+// reload reuses the same instance, so in-flight token buckets survive it.
+func (g *Server) rateLimiterFor(route Route) *rateLimiter {
+	rps := route.RateLimitRPS
+	if rps <= 0 {
+		rps = defaultRateLimitRPS
+	}
+	burst := route.RateLimitBurst
+	if burst <= 0 {
+		burst = defaultRateLimitBurst
+	}
+
+	g.limiterMu.Lock()
+	defer g.limiterMu.Unlock()
+
+	if l, ok := g.rateLimiters[route.Path]; ok && l.rate == rps && l.capacity == float64(burst) {
+		return l
+	} else if ok {
+		l.close()
+	}
+
+	l := newRateLimiter(rps, burst)
+	g.rateLimiters[route.Path] = l
+	return l
+}
+
+// pruneRateLimiters stops and discards limiters for routes that no
+// longer exist in livePaths. Without this, a route removed by a config
+// reload would leave its limiter's sweepLoop goroutine running forever
+// - rateLimiterFor only replaces a limiter it's asked for again, and a
+// removed route is never asked for again.
+func (g *Server) pruneRateLimiters(livePaths map[string]bool) {
+	g.limiterMu.Lock()
+	defer g.limiterMu.Unlock()
+	for path, l := range g.rateLimiters {
+		if !livePaths[path] {
+			l.close()
+			delete(g.rateLimiters, path)
+		}
+	}
+}
+
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.capacity, rate: l.rate, capacity: l.capacity, last: time.Now()}
+		l.buckets[key] = b
+	}
+	l.mu.Unlock()
+	return b.allow()
+}
+
+// newRateLimitMiddleware resolves the token-bucket rate limiter for
+// route.Path, sized from route.RateLimitRPS/RateLimitBurst (or the
+// package defaults, if unset) - one bucket per caller. A caller is
+// identified by its API key - the bearer token or basic-auth username,
+// whichever credential scheme the route uses - or by remote address for
+// unauthenticated routes, so a single abusive IP can't starve every
+// other caller's quota.
+//
+// The limiter itself is cached on g.rateLimiters keyed by route.Path, not
+// rebuilt here, so a config reload (buildChain runs again on every
+// SetConfig) doesn't hand every caller a fresh bucket.
+func newRateLimitMiddleware(route Route, g *Server) Middleware {
+	limiter := g.rateLimiterFor(route)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.allow(rateLimitKey(r)) {
+				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey picks the identity a rate limit is keyed on: whatever
+// credential the request already carries (so one API key gets one quota
+// no matter which address it calls from), falling back to remote
+// address for routes with no auth middleware in front of this one. The
+// address is truncated to its host, since r.RemoteAddr includes the
+// client's ephemeral source port - keying on the full "ip:port" would
+// hand a short-lived-connection client a fresh bucket every request.
+func rateLimitKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token := strings.TrimPrefix(auth, "Bearer "); token != auth {
+			return token
+		}
+		if user, _, ok := r.BasicAuth(); ok {
+			return user
+		}
+		return auth
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,486 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"senseai/internal/policy"
+	"senseai/internal/providers"
+)
+
+// Server is the inline reverse-proxy gateway: requests to a configured
+// route are inspected against policy.Engine before being forwarded
+// upstream, and responses (including SSE streams) are re-evaluated as
+// they come back, giving callers an in-line control point instead of
+// the passive detectors' observe-only view.
+//
+// Config can be swapped at runtime via SetConfig/WatchConfig, so routes
+// published to a Consul/etcd-backed policy.Source take effect without
+// restarting the listener.
+type Server struct {
+	Engine  *policy.Engine
+	Handler func(string, string, string, float64, *policy.Rule) // type, details, source, severity, rule
+
+	mu     sync.RWMutex
+	config *Config
+	mux    http.Handler
+
+	// limiterMu guards rateLimiters, the per-route-path rate limiter
+	// instances. These live on Server rather than being rebuilt by
+	// buildChain so their token buckets survive a SetConfig/WatchConfig
+	// reload - rebuilding them on every reload would hand every caller a
+	// fresh full bucket each time, turning "N requests/sec" into "N
+	// requests per reload interval".
+	limiterMu    sync.Mutex
+	rateLimiters map[string]*rateLimiter
+
+	// warnOnceMu guards warnedOnce, which dedupes startup-style
+	// misconfiguration warnings (e.g. auth.basic/auth.bearer with no
+	// credentials set) so a config-reload loop - buildChain runs again on
+	// every SetConfig - doesn't turn a one-line warning into a
+	// log-flooding one.
+	warnOnceMu sync.Mutex
+	warnedOnce map[string]bool
+}
+
+func NewServer(cfg *Config, engine *policy.Engine, handler func(string, string, string, float64, *policy.Rule)) *Server {
+	g := &Server{
+		Engine:       engine,
+		Handler:      handler,
+		rateLimiters: make(map[string]*rateLimiter),
+		warnedOnce:   make(map[string]bool),
+	}
+	g.config = cfg
+	g.mux = g.buildMux(cfg)
+	return g
+}
+
+// Config returns the gateway's current route configuration.
+func (g *Server) Config() *Config {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.config
+}
+
+// SetConfig atomically swaps in a new route configuration; in-flight
+// requests keep using the handler they started with, new requests use
+// the new one.
+func (g *Server) SetConfig(cfg *Config) {
+	mux := g.buildMux(cfg)
+	g.mu.Lock()
+	g.config = cfg
+	g.mux = mux
+	g.mu.Unlock()
+}
+
+// WatchConfig periodically fetches route config from source and applies
+// it via SetConfig. Like policy.Engine.Watch, a fetch/parse failure logs
+// a warning, keeps serving the last-known-good config, and backs off
+// exponentially before retrying - routing never silently degrades just
+// because the remote source hiccuped. Blocks until ctx is cancelled.
+func (g *Server) WatchConfig(ctx context.Context, source policy.Source, interval time.Duration) {
+	backoff := time.Second
+	const maxBackoff = 2 * time.Minute
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		cfg, err := LoadConfigFromSource(ctx, source)
+		if err != nil {
+			log.Printf("[gateway] failed to fetch config from %s, keeping last-known-good: %v", source.Name(), err)
+			if !sleepBackoff(ctx, backoff) {
+				return
+			}
+			backoff = nextBackoff(backoff, maxBackoff)
+			continue
+		}
+
+		g.SetConfig(cfg)
+		backoff = time.Second
+
+		if !sleepBackoff(ctx, interval) {
+			return
+		}
+	}
+}
+
+func (g *Server) buildMux(cfg *Config) http.Handler {
+	mux := http.NewServeMux()
+
+	livePaths := make(map[string]bool, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		livePaths[route.Path] = true
+	}
+	g.pruneRateLimiters(livePaths)
+
+	for _, route := range cfg.Routes {
+		targetURL, err := url.Parse(route.Target)
+		if err != nil {
+			log.Printf("[gateway] invalid target URL for route %s, skipping: %v", route.Path, err)
+			continue
+		}
+
+		proxy := httputil.NewSingleHostReverseProxy(targetURL)
+
+		// Custom Director to handle path rewriting if needed, or Auth headers
+		originalDirector := proxy.Director
+		proxy.Director = func(req *http.Request) {
+			originalDirector(req)
+			// Reset Host header to target's host (important for cloud CLIs)
+			req.Host = targetURL.Host
+			log.Printf("[Gateway OUT] %s -> %s%s", req.RemoteAddr, route.Target, req.URL.Path)
+		}
+
+		proxy.ModifyResponse = g.inspectResponse(route)
+
+		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("Proxy Error: %v", err)
+			http.Error(w, "Bad Gateway: "+err.Error(), http.StatusBadGateway)
+		}
+
+		final := g.guardLongRunning(route, g.guardUpgrade(route, proxy))
+		handler := g.buildChain(route, final)
+		mux.Handle(route.Path, handler)
+		fmt.Printf("Registered Route: %s -> %s (Provider: %s)\n", route.Path, route.Target, route.Provider)
+	}
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("SenseAI Gateway Operational"))
+	})
+
+	return mux
+}
+
+func (g *Server) Start() error {
+	cfg := g.Config()
+	addr := fmt.Sprintf(":%d", cfg.Server.Port)
+	fmt.Printf("SenseAI Gateway listening on %s\n", addr)
+
+	server := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			g.mu.RLock()
+			mux := g.mux
+			g.mu.RUnlock()
+			mux.ServeHTTP(w, r)
+		}),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	return server.ListenAndServe()
+}
+
+func sleepBackoff(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func nextBackoff(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next > max {
+		next = max
+	}
+	return next
+}
+
+// InspectionMiddleware is the "inspect.request" factory: it evaluates
+// the request body against policy.Engine before the request reaches the
+// reverse proxy. ActionBlock short circuits with a synthetic error
+// response; ActionKill closes the client connection outright (no
+// response at all - the request never makes it upstream); ActionAlert
+// lets the request through but still emits a finding. A WebSocket
+// handshake has no JSON body to evaluate - AllowUpgrade enforcement and
+// open/close tracking happen in guardUpgrade regardless, so this just
+// passes it straight through rather than trying to buffer it.
+//
+// ActionRedact falls into the default branch here and passes the request
+// through unmodified: rewriting what the caller sends upstream (as opposed
+// to what the model sends back, see inspectResponse below) isn't
+// implemented yet, so a rule meant to redact should target the response
+// side until request-side redaction lands.
+func (g *Server) InspectionMiddleware(route Route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodPost && r.Method != http.MethodPut {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bodyBytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Printf("Failed to read body: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+
+		data, ok := canonicalBody(route, bodyBytes)
+		if !ok {
+			if err := json.Unmarshal(bodyBytes, &data); err != nil {
+				// Not JSON - pass through without inspection.
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		rule := g.Engine.EvaluateJSON(data)
+		if rule == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		g.emitFinding("gateway", route, fmt.Sprintf("Gateway Policy Violation: %s", rule.Name), string(bodyBytes), rule)
+
+		switch rule.Action {
+		case policy.ActionBlock:
+			http.Error(w, fmt.Sprintf("Blocked by SenseAI Policy: %s", rule.Name), http.StatusForbidden)
+		case policy.ActionKill:
+			if hijacker, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hijacker.Hijack(); err == nil {
+					conn.Close()
+					return
+				}
+			}
+			// Hijacking isn't supported on this ResponseWriter - the closest
+			// equivalent is refusing the request outright.
+			http.Error(w, fmt.Sprintf("Terminated by SenseAI Policy: %s", rule.Name), http.StatusForbidden)
+		default: // policy.ActionAlert and anything else: pass through
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// inspectResponseCtxKey toggles response inspection on or off for a
+// given request. It exists because response inspection runs inside
+// httputil.ReverseProxy.ModifyResponse, which isn't itself a
+// func(http.Handler) http.Handler the way every other middleware is -
+// there's no "next" to wrap, since ModifyResponse fires deep inside the
+// proxy's RoundTrip. The "inspect.response" middleware instead stamps
+// the request context with this key; inspectResponse (always wired into
+// ModifyResponse in buildMux) checks for that stamp and skips itself if
+// it's absent, so routes that don't list "inspect.response" get none of
+// its cost.
+type inspectResponseCtxKey struct{}
+
+// inspectResponse builds a httputil.ReverseProxy.ModifyResponse hook
+// that re-evaluates the upstream response body against policy.Engine.
+// SSE responses (text/event-stream) are re-evaluated chunk by chunk as
+// they stream back, rather than buffered whole - ActionRedact only
+// applies to the buffered, non-streaming path below, since rewriting an
+// already-flushed SSE event in place isn't possible.
+func (g *Server) inspectResponse(route Route) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if resp.Request == nil || resp.Request.Context().Value(inspectResponseCtxKey{}) == nil {
+			return nil
+		}
+		// A 101's Body is the upgraded connection that ReverseProxy tunnels
+		// once this hook returns; reading or replacing it would break that.
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			return nil
+		}
+
+		if isSSE(resp) {
+			resp.Body = newInspectedSSEBody(resp.Body, g, route)
+			return nil
+		}
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+		data, ok := canonicalBody(route, bodyBytes)
+		if !ok {
+			if err := json.Unmarshal(bodyBytes, &data); err != nil {
+				return nil
+			}
+		}
+
+		rule := g.Engine.EvaluateJSON(data)
+		if rule == nil {
+			return nil
+		}
+
+		switch rule.Action {
+		case policy.ActionRedact:
+			return g.redactResponse(route, resp, bodyBytes, rule)
+		case policy.ActionBlock:
+			g.emitFinding("gateway.response", route, fmt.Sprintf("Gateway Response Policy Violation: %s", rule.Name), string(bodyBytes), rule)
+			return fmt.Errorf("blocked by SenseAI policy: %s", rule.Name)
+		default: // ActionAlert and anything else (e.g. ActionKill, meaningless once the response already exists)
+			g.emitFinding("gateway.response", route, fmt.Sprintf("Gateway Response Policy Violation: %s", rule.Name), string(bodyBytes), rule)
+		}
+
+		return nil
+	}
+}
+
+// redactResponse replaces every occurrence rule.Redact finds in the
+// upstream body with its «redacted:<rule.Name>» marker and rewrites resp
+// to carry the redacted bytes (including Content-Length, since the
+// redacted body is very unlikely to be the same length as the original),
+// so the caller still gets a response instead of a hard block. The
+// finding logged for this is "gateway.response.redacted" and - unlike the
+// ActionAlert/ActionBlock finding above - never carries the raw body:
+// what triggered the match is exactly what this is scrubbing.
+func (g *Server) redactResponse(route Route, resp *http.Response, bodyBytes []byte, rule *policy.Rule) error {
+	redacted, count := rule.Redact(string(bodyBytes))
+
+	resp.Body = io.NopCloser(strings.NewReader(redacted))
+	resp.ContentLength = int64(len(redacted))
+	resp.Header.Set("Content-Length", fmt.Sprintf("%d", len(redacted)))
+
+	sourceJSON, err := json.Marshal(map[string]interface{}{"rule": rule.Name, "redaction_count": count})
+	if err != nil {
+		sourceJSON = []byte("{}")
+	}
+	g.emitFinding("gateway.response.redacted", route, fmt.Sprintf("Gateway Response Redacted: %s (%d replacement(s))", rule.Name, count), string(sourceJSON), rule)
+
+	return nil
+}
+
+func init() {
+	RegisterMiddleware("inspect.request", func(route Route, g *Server) Middleware {
+		return func(next http.Handler) http.Handler {
+			return g.InspectionMiddleware(route, next)
+		}
+	})
+
+	// "inspect.response" has no inspection logic of its own - see
+	// inspectResponseCtxKey - it just flips the switch inspectResponse
+	// checks before doing any work.
+	RegisterMiddleware("inspect.response", func(route Route, g *Server) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := context.WithValue(r.Context(), inspectResponseCtxKey{}, true)
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		}
+	})
+
+	RegisterMiddleware("audit", func(route Route, g *Server) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				log.Printf("[gateway audit] %s %s %s from %s", route.Path, r.Method, r.URL.Path, r.RemoteAddr)
+				next.ServeHTTP(w, r)
+			})
+		}
+	})
+}
+
+// warnOnce logs format/args under log.Printf the first time key is seen
+// and is a no-op on every subsequent call with the same key, for the
+// life of the Server - see warnedOnce.
+func (g *Server) warnOnce(key, format string, args ...interface{}) {
+	g.warnOnceMu.Lock()
+	already := g.warnedOnce[key]
+	g.warnedOnce[key] = true
+	g.warnOnceMu.Unlock()
+
+	if !already {
+		log.Printf(format, args...)
+	}
+}
+
+// canonicalBody normalizes raw through the Normalizer registered for
+// route.Provider, returning the Canonical shape as a plain map ready for
+// policy.Engine.EvaluateJSON. ok is false when the provider has no
+// registered Normalizer or raw doesn't parse as that provider's body -
+// callers should fall back to evaluating raw JSON directly.
+//
+// A route with a recognized Provider trades the full raw body for this
+// narrower, provider-agnostic projection: only what Canonical models
+// (messages/system/tools/model/metadata) is visible to EvaluateJSON, so
+// a Recursive rule meant to catch anything anywhere in the body no
+// longer sees fields Canonical doesn't capture. That's the intended
+// effect of a route declaring its provider - Path rules written against
+// "$.messages[*].text" finally mean the same thing for every provider -
+// but it does mean Recursive rules are best paired with routes that
+// either leave Provider unset or don't rely on scanning outside the
+// conversation content.
+func canonicalBody(route Route, raw []byte) (map[string]interface{}, bool) {
+	n, ok := providers.Get(route.Provider)
+	if !ok {
+		return nil, false
+	}
+
+	canon, err := n.Normalize(raw)
+	if err != nil {
+		log.Printf("[gateway] failed to normalize %s body for route %s, falling back to raw JSON: %v", route.Provider, route.Path, err)
+		return nil, false
+	}
+
+	data, err := canon.ToJSON()
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// emitFinding forwards a finding to Handler, tagged with typ so
+// storage.Finding.Type distinguishes request-side matches ("gateway")
+// from response-side ones ("gateway.response") in the findings log.
+// route.Provider is folded into the finding's source JSON as "provider"
+// so a reviewer can tell which upstream API a match came from without
+// cross-referencing the route config.
+func (g *Server) emitFinding(typ string, route Route, details, source string, rule *policy.Rule) {
+	if g.Handler == nil {
+		return
+	}
+	severity := 0.0
+	if rule != nil {
+		severity = rule.Severity
+	}
+	g.Handler(typ, details, withProvider(source, route.Provider), severity, rule)
+}
+
+// withProvider folds a "provider" key into a JSON object source string.
+// source isn't always an object (inspectResponse passes the raw upstream
+// body verbatim, which could be anything), so a source that doesn't
+// unmarshal into a map is passed through unchanged rather than dropped.
+func withProvider(source, provider string) string {
+	if provider == "" {
+		return source
+	}
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(source), &meta); err != nil {
+		return source
+	}
+	meta["provider"] = provider
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return source
+	}
+	return string(data)
+}
+
+func isSSE(resp *http.Response) bool {
+	return strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream")
+}
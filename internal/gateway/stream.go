@@ -0,0 +1,228 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"senseai/internal/policy"
+	"senseai/internal/providers"
+)
+
+// sseEvalEveryEvents and sseEvalEveryBytes bound how often the
+// accumulated assistant reply is re-evaluated against policy.Engine -
+// evaluating on every single delta event would be wasteful for
+// token-by-token streams, so inspection instead runs whenever either
+// threshold is crossed.
+const (
+	sseEvalEveryEvents = 5
+	sseEvalEveryBytes  = 512
+)
+
+// inspectedSSEBody wraps an upstream SSE response body, reassembling the
+// "data: " events it streams back into a rolling buffer of the assistant
+// reply and re-evaluating that buffer against policy.Engine as it grows,
+// instead of only inspecting each raw delta chunk (which rarely matches
+// rules written against a normal {"role":"assistant","content":...}
+// shape). A matching rule emits a finding through the same handler the
+// request side uses, tagged "gateway.response"; ActionBlock/ActionKill
+// stop forwarding further events, close the upstream connection, and emit
+// a final "event: policy_block" frame so the client knows why the stream
+// ended rather than just seeing it cut off.
+type inspectedSSEBody struct {
+	upstream io.ReadCloser
+	scanner  *bufio.Scanner
+	route    Route
+	server   *Server
+
+	reply           bytes.Buffer
+	eventsSinceEval int
+	bytesSinceEval  int
+	alertedRules    map[string]bool
+	flushedTail     bool
+
+	pending       []byte
+	killed        bool
+	blockRuleName string
+}
+
+func newInspectedSSEBody(upstream io.ReadCloser, s *Server, route Route) io.ReadCloser {
+	scanner := bufio.NewScanner(upstream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &inspectedSSEBody{upstream: upstream, scanner: scanner, route: route, server: s}
+}
+
+func (b *inspectedSSEBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		if b.killed {
+			return 0, io.EOF
+		}
+
+		if !b.scanner.Scan() {
+			if err := b.scanner.Err(); err != nil {
+				return 0, err
+			}
+			// Stream ended cleanly (or hit [DONE]) with a trailing
+			// partial buffer that never crossed an eval threshold -
+			// give it one last look so a short reply isn't skipped.
+			if !b.flushedTail {
+				b.flushedTail = true
+				if b.evaluateReply() {
+					b.pending = policyBlockFrame(b.blockRuleName)
+					b.killed = true
+					continue
+				}
+			}
+			return 0, io.EOF
+		}
+
+		line := b.scanner.Text()
+		if b.inspectLine(line) {
+			b.pending = policyBlockFrame(b.blockRuleName)
+			b.killed = true
+			// Close the upstream connection outright rather than just
+			// stopping forwarding - we don't want the model to keep
+			// generating (and the provider billing us for) tokens no
+			// one downstream will ever see.
+			b.upstream.Close()
+			continue
+		}
+		b.pending = append([]byte(line), '\n')
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func (b *inspectedSSEBody) Close() error {
+	return b.upstream.Close()
+}
+
+// inspectLine folds a single SSE "data: ..." line's assistant-reply delta
+// into the rolling buffer and, once enough of it has accumulated,
+// re-evaluates the buffer as a whole. Returns true if the reply should be
+// blocked. OpenAI/Anthropic/Gemini all send one JSON chunk per data line
+// and terminate the stream with "data: [DONE]".
+func (b *inspectedSSEBody) inspectLine(line string) bool {
+	payload := strings.TrimPrefix(line, "data:")
+	payload = strings.TrimSpace(payload)
+	if payload == "" || payload == "[DONE]" {
+		return false
+	}
+
+	var chunk map[string]interface{}
+	if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+		return false
+	}
+
+	if delta := extractAssistantDelta(chunk); delta != "" {
+		b.reply.WriteString(delta)
+		b.bytesSinceEval += len(delta)
+	}
+	b.eventsSinceEval++
+
+	if b.eventsSinceEval < sseEvalEveryEvents && b.bytesSinceEval < sseEvalEveryBytes {
+		return false
+	}
+	b.eventsSinceEval = 0
+	b.bytesSinceEval = 0
+	return b.evaluateReply()
+}
+
+// evaluateReply runs the accumulated assistant reply through
+// policy.Engine.EvaluateJSON under a single-message Canonical shape
+// ({"messages":[{"role":"assistant","text":<buffer>}]}), the same shape
+// providers.Normalizer produces for a non-streamed response, so a rule
+// written once against $.messages[*].text catches streamed output from
+// any provider too. A rule that already matched earlier in this stream
+// is only reported once - without that, a non-blocking ActionAlert match
+// near the start of a long reply would re-fire on every subsequent
+// evaluation for the rest of the stream.
+func (b *inspectedSSEBody) evaluateReply() bool {
+	if b.reply.Len() == 0 {
+		return false
+	}
+
+	canonical := providers.Canonical{
+		Messages: []providers.Message{{Role: "assistant", Text: b.reply.String()}},
+	}
+	synthetic, err := canonical.ToJSON()
+	if err != nil {
+		return false
+	}
+
+	rule := b.server.Engine.EvaluateJSON(synthetic)
+	if rule == nil {
+		return false
+	}
+
+	if b.alertedRules == nil {
+		b.alertedRules = make(map[string]bool)
+	}
+	alreadyReported := b.alertedRules[rule.Name]
+	b.alertedRules[rule.Name] = true
+	if !alreadyReported {
+		b.server.emitFinding("gateway.response", b.route, fmt.Sprintf("Gateway Streaming Policy Violation: %s", rule.Name), b.reply.String(), rule)
+	}
+
+	switch rule.Action {
+	case policy.ActionBlock, policy.ActionKill:
+		b.blockRuleName = rule.Name
+		return true
+	}
+	return false
+}
+
+// policyBlockFrame is the final SSE event written to the client in place
+// of whatever the upstream would have sent next, so a blocked stream
+// looks like a deliberate stop rather than a dropped connection.
+func policyBlockFrame(ruleName string) []byte {
+	data, _ := json.Marshal(map[string]string{"rule": ruleName})
+	return []byte(fmt.Sprintf("event: policy_block\ndata: %s\n\n", data))
+}
+
+// extractAssistantDelta pulls the incremental assistant-reply text out of
+// a single SSE JSON chunk, trying each major provider's streaming delta
+// shape in turn. Returns "" if none match. This is deliberately minimal -
+// full provider-aware body normalization belongs to a dedicated layer,
+// not duplicated here just to reassemble a reply buffer.
+func extractAssistantDelta(chunk map[string]interface{}) string {
+	// OpenAI: choices[0].delta.content
+	if choices, ok := chunk["choices"].([]interface{}); ok && len(choices) > 0 {
+		if c0, ok := choices[0].(map[string]interface{}); ok {
+			if delta, ok := c0["delta"].(map[string]interface{}); ok {
+				if content, ok := delta["content"].(string); ok {
+					return content
+				}
+			}
+		}
+	}
+
+	// Anthropic: delta.text (content_block_delta events)
+	if delta, ok := chunk["delta"].(map[string]interface{}); ok {
+		if text, ok := delta["text"].(string); ok {
+			return text
+		}
+	}
+
+	// Gemini: candidates[0].content.parts[0].text
+	if candidates, ok := chunk["candidates"].([]interface{}); ok && len(candidates) > 0 {
+		if c0, ok := candidates[0].(map[string]interface{}); ok {
+			if content, ok := c0["content"].(map[string]interface{}); ok {
+				if parts, ok := content["parts"].([]interface{}); ok && len(parts) > 0 {
+					if p0, ok := parts[0].(map[string]interface{}); ok {
+						if text, ok := p0["text"].(string); ok {
+							return text
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
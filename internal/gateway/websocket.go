@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// isWebSocketUpgrade reports whether r is a WebSocket handshake request:
+// Connection: Upgrade plus Upgrade: websocket. httputil.ReverseProxy
+// already hijacks and raw-copies these requests once the backend answers
+// with 101 Switching Protocols; ModifyResponse still runs for that 101,
+// and inspectResponse passes it through untouched - there's
+// no JSON body to inspect, and buffering the handshake the way
+// InspectionMiddleware buffers a POST/PUT body would just break it.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") &&
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+func headerHasToken(h http.Header, key, token string) bool {
+	for _, v := range h.Values(key) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wsTrackingWriter wraps the route's ResponseWriter for an upgrade
+// request so the gateway can observe when the reverse proxy actually
+// hijacks the connection (the handshake succeeded and the tunnel is
+// live) and when that tunnel closes. It forwards Hijack and Flush to the
+// underlying ResponseWriter rather than implementing them itself -
+// without that forwarding, wrapping would break both the proxy's
+// upgrade handoff (which type-asserts for http.Hijacker) and ordinary
+// streaming responses (which type-assert for http.Flusher).
+type wsTrackingWriter struct {
+	http.ResponseWriter
+	onOpen  func()
+	onClose func()
+}
+
+func (w *wsTrackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("gateway: underlying ResponseWriter does not support hijacking")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return conn, rw, err
+	}
+
+	if w.onOpen != nil {
+		w.onOpen()
+	}
+
+	// TODO: frame-level policy would intercept Read/Write on conn here
+	// instead of only tracking lifecycle - for now an upgraded connection
+	// is a blind tunnel once open.
+	return &wsCloseTrackingConn{Conn: conn, onClose: w.onClose}, rw, nil
+}
+
+func (w *wsTrackingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// wsCloseTrackingConn fires onClose the first time Close is called. Close
+// can be invoked from either side of the tunnel's raw-copy loop as it
+// tears down, so onClose is deduped with sync.Once rather than firing
+// once per Close call.
+type wsCloseTrackingConn struct {
+	net.Conn
+	once    sync.Once
+	onClose func()
+}
+
+func (c *wsCloseTrackingConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+	return err
+}
+
+// guardUpgrade enforces route.AllowUpgrade for every route, regardless
+// of which named middlewares it lists. It wraps final - the proxy
+// handler buildMux hands to buildChain - rather than living inside
+// "inspect.request", so a route that omits "inspect.request" from its
+// Middlewares (e.g. to skip body-inspection overhead) still can't have a
+// WebSocket handshake silently tunnel straight through uninspected.
+// auth/ratelimit/audit middlewares still run first, since buildChain
+// wraps this handler the same as any other final handler.
+func (g *Server) guardUpgrade(route Route, final http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWebSocketUpgrade(r) {
+			g.handleUpgrade(route, final, w, r)
+			return
+		}
+		final.ServeHTTP(w, r)
+	})
+}
+
+// handleUpgrade is guardUpgrade's entry point for WebSocket handshakes:
+// routes without AllowUpgrade refuse the upgrade outright, and routes
+// with it skip straight to next (no body to buffer, no JSON to
+// evaluate), wrapped so the gateway can record when the tunnel opens
+// and closes instead of inspecting it.
+func (g *Server) handleUpgrade(route Route, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if !route.AllowUpgrade {
+		http.Error(w, "WebSocket upgrade not permitted for this route", http.StatusForbidden)
+		return
+	}
+
+	wrapped := &wsTrackingWriter{
+		ResponseWriter: w,
+		onOpen: func() {
+			g.emitFinding("gateway.websocket.opened", route, fmt.Sprintf("WebSocket connection opened on route %s", route.Path), "{}", nil)
+		},
+		onClose: func() {
+			g.emitFinding("gateway.websocket.closed", route, fmt.Sprintf("WebSocket connection closed on route %s", route.Path), "{}", nil)
+		},
+	}
+	next.ServeHTTP(wrapped, r)
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,21 +14,31 @@ import (
 type Action string
 
 const (
-	ActionAlert Action = "alert"
-	ActionBlock Action = "block"
-	ActionKill  Action = "kill"
+	ActionAlert  Action = "alert"
+	ActionBlock  Action = "block"
+	ActionKill   Action = "kill"
+	ActionRedact Action = "redact"
 )
 
 type Rule struct {
-	Name          string   `yaml:"name" json:"name"`
-	Description   string   `yaml:"description" json:"description"`
-	Target        string   `yaml:"target" json:"target"` // "network", "endpoint", "payload", "json_body"
-	Match         []string `yaml:"match,omitempty" json:"match,omitempty"`
-	Regex         string   `yaml:"regex,omitempty" json:"regex,omitempty"`
-	JsonKey       string   `yaml:"json_key,omitempty" json:"json_key,omitempty"` // e.g. "messages" or "prompt"
-	Action        Action   `yaml:"action" json:"action"`
-	Severity      float64  `yaml:"severity" json:"severity"`
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Target      string   `yaml:"target" json:"target"` // "network", "endpoint", "payload", "json_body"
+	Match       []string `yaml:"match,omitempty" json:"match,omitempty"`
+	Regex       string   `yaml:"regex,omitempty" json:"regex,omitempty"`
+	// Path is a JSONPath-like selector for json_body rules, e.g.
+	// "$.messages[*].content", "$.input", "$.contents[*].parts[*].text".
+	// Each dot-separated segment may end in "[*]" to walk every element
+	// of an array at that point; the string leaves found at the end of
+	// the path are matched against Match/Regex same as Evaluate's input.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Recursive walks every string leaf in the body when Path is unset,
+	// for rules that don't know the provider's body shape ahead of time.
+	Recursive     bool    `yaml:"recursive,omitempty" json:"recursive,omitempty"`
+	Action        Action  `yaml:"action" json:"action"`
+	Severity      float64 `yaml:"severity" json:"severity"`
 	regexCompiled *regexp.Regexp
+	pathSegments  []jsonPathSegment
 }
 
 func SavePolicies(path string, rules []Rule) error {
@@ -58,39 +69,26 @@ func NewEngine(path string) (*Engine, error) {
 }
 
 func (e *Engine) Load(path string) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
-	var config struct {
-		Policies []Rule `yaml:"policies"`
-	}
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	rules, err := parseRules(data)
+	if err != nil {
 		return err
 	}
 
-	for i := range config.Policies {
-		if config.Policies[i].Regex != "" {
-			re, err := regexp.Compile(config.Policies[i].Regex)
-			if err != nil {
-				fmt.Printf("[WARN] Failed to compile regex for policy '%s': %v (pattern: %s)\n",
-					config.Policies[i].Name, err, config.Policies[i].Regex)
-			} else {
-				config.Policies[i].regexCompiled = re
-			}
-		}
-	}
-
-	e.Rules = config.Policies
+	e.mu.Lock()
+	e.Rules = rules
+	e.mu.Unlock()
 	return nil
 }
 
 // Evaluate checks input against rules and returns the first matching rule (or nil)
 func (e *Engine) Evaluate(target, input string) *Rule {
+	defer observeEvalDuration(target, time.Now())
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -118,8 +116,14 @@ func (e *Engine) Evaluate(target, input string) *Rule {
 	return nil
 }
 
-// EvaluateJSON checks a map[string]interface{} against rules
+// EvaluateJSON checks a map[string]interface{} against json_body rules.
+// Each rule drills into the body with Path (a JSONPath-like selector) or,
+// if Path is unset, with Recursive to walk every string leaf in the
+// document; either way every string leaf found is matched the same way
+// Evaluate's input is.
 func (e *Engine) EvaluateJSON(data map[string]interface{}) *Rule {
+	defer observeEvalDuration("json_body", time.Now())
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -128,31 +132,18 @@ func (e *Engine) EvaluateJSON(data map[string]interface{}) *Rule {
 			continue
 		}
 
-		// Extract value from JSON based on JsonKey
-		// Simple implementation: check if key exists at top level for now,
-		// or recursive search if needed. For LLM, we usually care about "messages" or "prompt"
-		val, ok := data[rule.JsonKey]
-		if !ok {
-			// If key not found, maybe search recursively?
-			// For now, simple top-level or specific structure support.
+		var leaves []string
+		switch {
+		case rule.Path != "":
+			leaves = jsonPathLeaves(data, rule.pathSegments)
+		case rule.Recursive:
+			leaves = stringLeaves(data)
+		default:
 			continue
 		}
 
-		// Convert value to string for matching
-		strVal := fmt.Sprintf("%v", val)
-
-		// If it's a list of messages (OpenAI format), we might need to iterate
-		if list, isList := val.([]interface{}); isList {
-			for _, item := range list {
-				// Naive string conversion of the whole object to search for patterns
-				// Ideally we'd drill down to "content"
-				itemStr := fmt.Sprintf("%v", item)
-				if e.matches(rule, itemStr) {
-					return &rule
-				}
-			}
-		} else {
-			if e.matches(rule, strVal) {
+		for _, leaf := range leaves {
+			if e.matches(rule, leaf) {
 				return &rule
 			}
 		}
@@ -160,6 +151,144 @@ func (e *Engine) EvaluateJSON(data map[string]interface{}) *Rule {
 	return nil
 }
 
+// jsonPathSegment is one dot-separated step of a parsed JSONPath-like
+// selector - a map key, optionally followed by "[*]" to walk every
+// element of the array found there.
+type jsonPathSegment struct {
+	key      string
+	wildcard bool
+}
+
+// parseJSONPath turns a selector like "$.messages[*].content" into the
+// segments jsonPathLeaves walks. A leading "$" or "$." is optional and
+// stripped if present.
+func parseJSONPath(path string) []jsonPathSegment {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil
+	}
+
+	parts := strings.Split(path, ".")
+	segments := make([]jsonPathSegment, 0, len(parts))
+	for _, p := range parts {
+		seg := jsonPathSegment{key: p}
+		if strings.HasSuffix(p, "[*]") {
+			seg.key = strings.TrimSuffix(p, "[*]")
+			seg.wildcard = true
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+// jsonPathLeaves walks data along segments and returns every string leaf
+// found at the end of the path. A wildcard segment fans out into every
+// element of the array at that point; once the path is exhausted, any
+// remaining nested structure (object or array) is walked recursively via
+// stringLeaves so "$.input" still finds strings nested under it.
+func jsonPathLeaves(data interface{}, segments []jsonPathSegment) []string {
+	if len(segments) == 0 {
+		return stringLeaves(data)
+	}
+
+	seg := segments[0]
+	rest := segments[1:]
+
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	val, ok := m[seg.key]
+	if !ok {
+		return nil
+	}
+
+	if !seg.wildcard {
+		return jsonPathLeaves(val, rest)
+	}
+
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, item := range list {
+		out = append(out, jsonPathLeaves(item, rest)...)
+	}
+	return out
+}
+
+// stringLeaves recursively collects every matchable leaf nested anywhere
+// inside data, walking maps and slices. Scalars (numbers, bools) are
+// stringified the same way Evaluate's callers would, same as the
+// top-level lookup this replaced; nil is dropped since it never matches.
+func stringLeaves(data interface{}) []string {
+	switch v := data.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case map[string]interface{}:
+		var out []string
+		for _, item := range v {
+			out = append(out, stringLeaves(item)...)
+		}
+		return out
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			out = append(out, stringLeaves(item)...)
+		}
+		return out
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+// Redact replaces every occurrence of this rule's Match terms (matched
+// case-insensitively, same as Evaluate/EvaluateJSON) and Regex pattern
+// found in text with «redacted:<rule.Name>», and reports how many
+// replacements were made. It's the ActionRedact counterpart to
+// Evaluate/EvaluateJSON: those decide whether a rule fires, this is what
+// scrubs the body once one has - over the raw text, not the parsed leaf,
+// so a gateway response can be redacted and re-sent as-is.
+func (r Rule) Redact(text string) (string, int) {
+	count := 0
+	tag := "«redacted:" + r.Name + "»"
+
+	for _, m := range r.Match {
+		if m == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(m))
+		if err != nil {
+			continue
+		}
+		text = re.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return tag
+		})
+	}
+
+	if re := r.regexCompiled; re != nil {
+		// matches()/Evaluate test this regex against a lowercased leaf, so a
+		// pattern written in lowercase (the common case) still catches
+		// mixed-case input there. Redact runs over the raw, original-case
+		// body it's about to send back out, so it needs the same
+		// case-insensitivity made explicit instead of inherited for free.
+		if ci, err := regexp.Compile("(?i)" + re.String()); err == nil {
+			re = ci
+		}
+		text = re.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return tag
+		})
+	}
+
+	return text, count
+}
+
 func (e *Engine) matches(rule Rule, input string) bool {
 	input = strings.ToLower(input)
 
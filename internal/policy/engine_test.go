@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newJSONRule(path string, match []string, recursive bool) Rule {
+	r := Rule{
+		Target:    "json_body",
+		Path:      path,
+		Match:     match,
+		Recursive: recursive,
+		Action:    ActionAlert,
+	}
+	if path != "" {
+		r.pathSegments = parseJSONPath(path)
+	}
+	return r
+}
+
+func TestEvaluateJSON_OpenAIChatMessages(t *testing.T) {
+	e := &Engine{Rules: []Rule{
+		newJSONRule("$.messages[*].content", []string{"api_key"}, false),
+	}}
+
+	data := map[string]interface{}{
+		"model": "gpt-4",
+		"messages": []interface{}{
+			map[string]interface{}{"role": "system", "content": "be helpful"},
+			map[string]interface{}{"role": "user", "content": "here is my api_key: sk-abc123"},
+		},
+	}
+
+	if rule := e.EvaluateJSON(data); rule == nil {
+		t.Fatal("expected a match drilling into messages[*].content, got nil")
+	}
+}
+
+func TestEvaluateJSON_AnthropicMessages(t *testing.T) {
+	e := &Engine{Rules: []Rule{
+		newJSONRule("$.messages[*].content", []string{"ssn"}, false),
+	}}
+
+	data := map[string]interface{}{
+		"model": "claude-3",
+		"messages": []interface{}{
+			map[string]interface{}{"role": "user", "content": "what's my ssn 123-45-6789?"},
+		},
+	}
+
+	if rule := e.EvaluateJSON(data); rule == nil {
+		t.Fatal("expected a match on Anthropic messages shape, got nil")
+	}
+}
+
+func TestEvaluateJSON_GeminiContentsParts(t *testing.T) {
+	e := &Engine{Rules: []Rule{
+		newJSONRule("$.contents[*].parts[*].text", []string{"secret"}, false),
+	}}
+
+	data := map[string]interface{}{
+		"contents": []interface{}{
+			map[string]interface{}{
+				"parts": []interface{}{
+					map[string]interface{}{"text": "nothing here"},
+					map[string]interface{}{"text": "our secret project name is X"},
+				},
+			},
+		},
+	}
+
+	if rule := e.EvaluateJSON(data); rule == nil {
+		t.Fatal("expected a match drilling into contents[*].parts[*].text, got nil")
+	}
+}
+
+func TestEvaluateJSON_PathMiss(t *testing.T) {
+	e := &Engine{Rules: []Rule{
+		newJSONRule("$.messages[*].content", []string{"api_key"}, false),
+	}}
+
+	data := map[string]interface{}{
+		"messages": []interface{}{
+			map[string]interface{}{"role": "user", "content": "nothing sensitive here"},
+		},
+	}
+
+	if rule := e.EvaluateJSON(data); rule != nil {
+		t.Fatalf("expected no match, got rule %q", rule.Name)
+	}
+}
+
+func TestRule_Redact_MatchTerm(t *testing.T) {
+	r := Rule{Name: "api-key-leak", Match: []string{"sk-abc123"}, Action: ActionRedact}
+
+	redacted, count := r.Redact(`{"content":"here is my key SK-ABC123 again"}`)
+
+	if count != 1 {
+		t.Fatalf("expected 1 replacement, got %d", count)
+	}
+	if strings.Contains(strings.ToLower(redacted), "sk-abc123") {
+		t.Fatalf("expected match to be redacted, got %q", redacted)
+	}
+	if !strings.Contains(redacted, "«redacted:api-key-leak»") {
+		t.Fatalf("expected redaction marker tagged with rule name, got %q", redacted)
+	}
+}
+
+func TestRule_Redact_Regex(t *testing.T) {
+	r := Rule{Name: "ssn-leak", Action: ActionRedact}
+	r.regexCompiled = regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)
+
+	redacted, count := r.Redact("call me back, my ssn is 123-45-6789")
+
+	if count != 1 {
+		t.Fatalf("expected 1 replacement, got %d", count)
+	}
+	if strings.Contains(redacted, "123-45-6789") {
+		t.Fatalf("expected ssn to be redacted, got %q", redacted)
+	}
+}
+
+func TestEvaluateJSON_RecursiveFallback(t *testing.T) {
+	e := &Engine{Rules: []Rule{
+		newJSONRule("", []string{"api_key"}, true),
+	}}
+
+	// No Path set - a shape the rule author never anticipated should
+	// still be caught by the Recursive walk.
+	data := map[string]interface{}{
+		"input": map[string]interface{}{
+			"nested": []interface{}{
+				"plain string",
+				map[string]interface{}{"deep": "leaked api_key: sk-xyz"},
+			},
+		},
+	}
+
+	if rule := e.EvaluateJSON(data); rule == nil {
+		t.Fatal("expected the recursive fallback to find the nested leaf, got nil")
+	}
+}
@@ -0,0 +1,26 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// policyEvalDuration times Evaluate/EvaluateJSON calls, registered on the
+// default registerer at init so it shows up on whatever /metrics endpoint
+// the binary eventually exposes - see internal/proxy/metrics.go for the
+// same pattern.
+var policyEvalDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "senseai_policy_eval_duration_seconds",
+	Help: "Time spent evaluating policy rules, by target (network, endpoint, payload, json_body).",
+}, []string{"target"})
+
+func init() {
+	prometheus.MustRegister(policyEvalDuration)
+}
+
+// observeEvalDuration is called via defer from Evaluate/EvaluateJSON with
+// the call's start time.
+func observeEvalDuration(target string, start time.Time) {
+	policyEvalDuration.WithLabelValues(target).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,211 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source fetches the raw YAML policy document from wherever it lives -
+// a local file, a Consul/etcd KV entry, or a plain HTTP endpoint - so
+// operators running SENSE across a fleet can centrally publish new
+// detection rules without redeploying.
+type Source interface {
+	// Fetch returns the raw YAML policy document (the same "policies:"
+	// shape Load parses from disk).
+	Fetch(ctx context.Context) ([]byte, error)
+	// Name identifies the source for logging.
+	Name() string
+}
+
+// FileSource reads the policy document from a local path, same as the
+// original Load, so a local file fits the same Source interface as
+// Consul/HTTP. The gateway's --policy-source=file does not use it; that
+// mode loads the file once through NewEngine and watches no Source.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Fetch(ctx context.Context) ([]byte, error) {
+	return os.ReadFile(s.Path)
+}
+
+func (s FileSource) Name() string {
+	return fmt.Sprintf("file:%s", s.Path)
+}
+
+// ConsulSource reads the policy document from a Consul KV key, using the
+// agent's HTTP API with ?raw=true so the response body is the value
+// itself rather than Consul's base64-wrapped KV envelope.
+type ConsulSource struct {
+	Addr   string // e.g. "http://127.0.0.1:8500"
+	Key    string // e.g. "senseai/policies"
+	Client *http.Client
+}
+
+func (s ConsulSource) Fetch(ctx context.Context) ([]byte, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	u := fmt.Sprintf("%s/v1/kv/%s?raw=true", s.Addr, url.PathEscape(s.Key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul returned status %d for key %s", resp.StatusCode, s.Key)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (s ConsulSource) Name() string {
+	return fmt.Sprintf("consul:%s/%s", s.Addr, s.Key)
+}
+
+// HTTPSource reads the policy document from a plain HTTP(S) endpoint,
+// for operators serving policies from any key/value store (etcd
+// gateway, S3 presigned URL, internal config service) behind a regular
+// GET.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s HTTPSource) Fetch(ctx context.Context) ([]byte, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy source returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (s HTTPSource) Name() string {
+	return fmt.Sprintf("http:%s", s.URL)
+}
+
+// parseRules decodes the "policies:" YAML document shared by Load and
+// every Source, compiling each rule's regex the same way.
+func parseRules(data []byte) ([]Rule, error) {
+	var config struct {
+		Policies []Rule `yaml:"policies"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	for i := range config.Policies {
+		if config.Policies[i].Regex != "" {
+			re, err := regexp.Compile(config.Policies[i].Regex)
+			if err != nil {
+				fmt.Printf("[WARN] Failed to compile regex for policy '%s': %v (pattern: %s)\n",
+					config.Policies[i].Name, err, config.Policies[i].Regex)
+			} else {
+				config.Policies[i].regexCompiled = re
+			}
+		}
+		if config.Policies[i].Path != "" {
+			config.Policies[i].pathSegments = parseJSONPath(config.Policies[i].Path)
+		}
+	}
+
+	return config.Policies, nil
+}
+
+// Watch starts a background loop that periodically fetches rules from
+// source and atomically swaps e.Rules when they change. If source
+// becomes unavailable, Watch logs a warning, keeps serving the
+// last-known-good ruleset, and backs off exponentially before retrying
+// - detection never silently degrades to an empty ruleset just because
+// the remote source hiccuped. Watch blocks until ctx is cancelled.
+func (e *Engine) Watch(ctx context.Context, source Source, interval time.Duration) {
+	backoff := time.Second
+	const maxBackoff = 2 * time.Minute
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		data, err := source.Fetch(ctx)
+		if err != nil {
+			log.Printf("[policy] failed to fetch rules from %s, keeping last-known-good: %v", source.Name(), err)
+			if !sleepBackoff(ctx, backoff) {
+				return
+			}
+			backoff = nextBackoff(backoff, maxBackoff)
+			continue
+		}
+
+		rules, err := parseRules(data)
+		if err != nil {
+			log.Printf("[policy] failed to parse rules from %s, keeping last-known-good: %v", source.Name(), err)
+			if !sleepBackoff(ctx, backoff) {
+				return
+			}
+			backoff = nextBackoff(backoff, maxBackoff)
+			continue
+		}
+
+		e.mu.Lock()
+		e.Rules = rules
+		e.mu.Unlock()
+		backoff = time.Second
+
+		if !sleepBackoff(ctx, interval) {
+			return
+		}
+	}
+}
+
+// sleepBackoff waits for d or ctx cancellation, reporting whether the
+// wait completed normally (false means the caller should stop).
+func sleepBackoff(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func nextBackoff(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next > max {
+		next = max
+	}
+	return next
+}
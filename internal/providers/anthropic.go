@@ -0,0 +1,109 @@
+package providers
+
+import "encoding/json"
+
+// anthropicNormalizer handles both the Messages API request body
+// ({"model", "system", "messages": [{"role", "content"}]}) and the
+// response body ({"model", "role", "content": [...]}), where content is
+// always an array of blocks rather than a plain string. A "text" block
+// contributes its text directly; a "tool_use" block (the model invoking
+// a tool - historically called an "input_json" block while it's still
+// streaming in as a content_block_delta) becomes a ToolCall instead of
+// message text.
+type anthropicNormalizer struct{}
+
+func (anthropicNormalizer) Normalize(raw []byte) (Canonical, error) {
+	var body struct {
+		Model    string                 `json:"model"`
+		System   json.RawMessage        `json:"system"`
+		Role     string                 `json:"role"`
+		Content  json.RawMessage        `json:"content"`
+		Metadata map[string]interface{} `json:"metadata"`
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return Canonical{}, err
+	}
+
+	c := Canonical{Model: body.Model, System: anthropicContentText(body.System), Metadata: body.Metadata}
+
+	for _, m := range body.Messages {
+		text, tools := anthropicContentBlocks(m.Content)
+		c.Messages = append(c.Messages, Message{Role: m.Role, Text: text})
+		c.Tools = append(c.Tools, tools...)
+	}
+
+	// Response shape: a single top-level "content" array and "role"
+	// instead of a "messages" array.
+	if len(body.Content) > 0 {
+		text, tools := anthropicContentBlocks(body.Content)
+		role := body.Role
+		if role == "" {
+			role = "assistant"
+		}
+		c.Messages = append(c.Messages, Message{Role: role, Text: text})
+		c.Tools = append(c.Tools, tools...)
+	}
+
+	return c, nil
+}
+
+// anthropicContentText flattens Anthropic's "system" field, which is
+// either a plain string or an array of {"type":"text","text":...} blocks.
+func anthropicContentText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	text, _ := anthropicContentBlocks(raw)
+	return text
+}
+
+// anthropicContentBlocks flattens a message "content" field - either a
+// plain string or an array of content blocks - into its text blocks
+// joined together plus any tool_use blocks pulled out as ToolCalls.
+func anthropicContentBlocks(raw json.RawMessage) (string, []ToolCall) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+
+	var blocks []struct {
+		Type     string                 `json:"type"`
+		Text     string                 `json:"text"`
+		Thinking string                 `json:"thinking"`
+		Name     string                 `json:"name"`
+		Input    map[string]interface{} `json:"input"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return "", nil
+	}
+
+	var text string
+	var tools []ToolCall
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			text += b.Text
+		case "thinking":
+			// Extended-thinking content is plain text too, and just as
+			// capable of carrying the sensitive content a policy rule is
+			// looking for - only "redacted_thinking" blocks (the
+			// encrypted variant) have nothing inspectable.
+			text += b.Thinking
+		case "tool_use":
+			tools = append(tools, ToolCall{Name: b.Name, Input: b.Input})
+		}
+	}
+	return text, tools
+}
@@ -0,0 +1,52 @@
+package providers
+
+import "encoding/json"
+
+// bedrockNormalizer handles the handful of model-family body shapes the
+// Bedrock Runtime InvokeModel API passes through unwrapped (the model ID
+// lives in the URL path, not the body, so unlike the other providers
+// there's no "model" field to read here). Anthropic models hosted on
+// Bedrock use the same Messages API shape as the direct Anthropic API,
+// just without a "model" field, so that case delegates to
+// anthropicNormalizer. Amazon Titan and Meta Llama use their own much
+// simpler single-prompt shapes.
+type bedrockNormalizer struct{}
+
+func (bedrockNormalizer) Normalize(raw []byte) (Canonical, error) {
+	var probe struct {
+		AnthropicVersion string `json:"anthropic_version"`
+		InputText        string `json:"inputText"`
+		Prompt           string `json:"prompt"`
+		Results          []struct {
+			OutputText string `json:"outputText"`
+		} `json:"results"`
+		Generation string `json:"generation"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return Canonical{}, err
+	}
+
+	if probe.AnthropicVersion != "" {
+		return anthropicNormalizer{}.Normalize(raw)
+	}
+
+	c := Canonical{}
+	switch {
+	case probe.InputText != "":
+		// Titan request: {"inputText": "...", "textGenerationConfig": {...}}
+		c.Messages = append(c.Messages, Message{Role: "user", Text: probe.InputText})
+	case len(probe.Results) > 0:
+		// Titan response: {"results": [{"outputText": "..."}]}
+		for _, r := range probe.Results {
+			c.Messages = append(c.Messages, Message{Role: "assistant", Text: r.OutputText})
+		}
+	case probe.Prompt != "":
+		// Llama request: {"prompt": "...", "max_gen_len": ...}
+		c.Messages = append(c.Messages, Message{Role: "user", Text: probe.Prompt})
+	case probe.Generation != "":
+		// Llama response: {"generation": "...", "stop_reason": "..."}
+		c.Messages = append(c.Messages, Message{Role: "assistant", Text: probe.Generation})
+	}
+
+	return c, nil
+}
@@ -0,0 +1,73 @@
+package providers
+
+import "encoding/json"
+
+// geminiNormalizer handles both the generateContent request body
+// ({"contents": [{"role","parts":[{"text"}]}], "systemInstruction"}) and
+// the response body ({"candidates": [{"content": {"role","parts"}}]}).
+type geminiNormalizer struct{}
+
+type geminiFunctionCall struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args"`
+}
+
+type geminiPart struct {
+	Text         string              `json:"text"`
+	FunctionCall *geminiFunctionCall `json:"functionCall"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role"`
+	Parts []geminiPart `json:"parts"`
+}
+
+func (geminiNormalizer) Normalize(raw []byte) (Canonical, error) {
+	var body struct {
+		Model             string          `json:"model"`
+		SystemInstruction *geminiContent  `json:"systemInstruction"`
+		Contents          []geminiContent `json:"contents"`
+		Candidates        []struct {
+			Content geminiContent `json:"content"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return Canonical{}, err
+	}
+
+	c := Canonical{Model: body.Model}
+	if body.SystemInstruction != nil {
+		c.System = geminiPartsText(body.SystemInstruction.Parts)
+	}
+
+	flatten := func(ct geminiContent) {
+		role := ct.Role
+		if role == "" {
+			role = "model"
+		}
+		c.Messages = append(c.Messages, Message{Role: role, Text: geminiPartsText(ct.Parts)})
+		for _, p := range ct.Parts {
+			if p.FunctionCall != nil {
+				c.Tools = append(c.Tools, ToolCall{Name: p.FunctionCall.Name, Input: p.FunctionCall.Args})
+			}
+		}
+	}
+
+	for _, ct := range body.Contents {
+		flatten(ct)
+	}
+	// Response shape: candidates[].content instead of top-level contents.
+	for _, cand := range body.Candidates {
+		flatten(cand.Content)
+	}
+
+	return c, nil
+}
+
+func geminiPartsText(parts []geminiPart) string {
+	var text string
+	for _, p := range parts {
+		text += p.Text
+	}
+	return text
+}
@@ -0,0 +1,95 @@
+package providers
+
+import "encoding/json"
+
+// openAINormalizer handles both the chat completion request body
+// ({"model", "messages": [{"role","content"}], "tools"}) and the
+// non-streaming response body ({"model", "choices": [{"message": {...}}]}).
+// Streaming chunks never reach a Normalizer: gateway.inspectedSSEBody
+// reassembles their deltas into a Canonical itself.
+type openAINormalizer struct{}
+
+type openAIToolCall struct {
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAIMessage covers both a request message and the message embedded
+// in a response choice - ToolCalls is only ever populated on an
+// assistant message, whichever shape it appears in.
+type openAIMessage struct {
+	Role      string           `json:"role"`
+	Content   json.RawMessage  `json:"content"`
+	ToolCalls []openAIToolCall `json:"tool_calls"`
+}
+
+func (openAINormalizer) Normalize(raw []byte) (Canonical, error) {
+	var body struct {
+		Model    string          `json:"model"`
+		User     string          `json:"user"`
+		Messages []openAIMessage `json:"messages"`
+		Choices  []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return Canonical{}, err
+	}
+
+	c := Canonical{Model: body.Model}
+	if body.User != "" {
+		c.Metadata = map[string]interface{}{"user": body.User}
+	}
+
+	flatten := func(m openAIMessage) {
+		c.Messages = append(c.Messages, Message{Role: m.Role, Text: openAIContentText(m.Content)})
+		for _, tc := range m.ToolCalls {
+			var input map[string]interface{}
+			json.Unmarshal([]byte(tc.Function.Arguments), &input)
+			c.Tools = append(c.Tools, ToolCall{Name: tc.Function.Name, Input: input})
+		}
+	}
+
+	for _, m := range body.Messages {
+		flatten(m)
+	}
+	// Response shape: no top-level "messages", the reply (and any tool
+	// calls the assistant made) lives in choices[].message instead.
+	for _, choice := range body.Choices {
+		flatten(choice.Message)
+	}
+
+	return c, nil
+}
+
+// openAIContentText flattens an OpenAI "content" field, which is either a
+// plain string or an array of multimodal content parts
+// ([{"type":"text","text":"..."}, {"type":"image_url", ...}]), into the
+// text portion alone.
+func openAIContentText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var text string
+	for _, p := range parts {
+		if p.Type == "text" {
+			text += p.Text
+		}
+	}
+	return text
+}
@@ -0,0 +1,80 @@
+// Package providers normalizes provider-specific chat completion bodies
+// (OpenAI, Anthropic, Gemini, Bedrock) into one canonical shape, so a
+// single policy.Rule written against "$.messages[*].text" matches a
+// request or response no matter which upstream API it's headed to or
+// came from, instead of needing one rule per provider's wire format.
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Message is one canonical chat turn. Text is the flattened text content
+// of the turn - providers that split a turn into multiple content blocks
+// (Anthropic, Gemini) have those blocks' text joined into one string.
+type Message struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// ToolCall is a canonical view of a model-invoked tool/function call,
+// gathered from whatever shape the provider represents it in (OpenAI's
+// message.tool_calls, Anthropic's "tool_use" content blocks, Gemini's
+// functionCall parts).
+type ToolCall struct {
+	Name  string                 `json:"name"`
+	Input map[string]interface{} `json:"input,omitempty"`
+}
+
+// Canonical is the provider-agnostic shape every Normalizer produces.
+// Policy rules with a json_body Target are written against this shape,
+// not against any one provider's native body.
+type Canonical struct {
+	Model    string                 `json:"model,omitempty"`
+	System   string                 `json:"system,omitempty"`
+	Messages []Message              `json:"messages"`
+	Tools    []ToolCall             `json:"tools,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ToJSON round-trips Canonical through its json tags into the
+// map[string]interface{} shape policy.Engine.EvaluateJSON expects.
+func (c Canonical) ToJSON() (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Normalizer turns a provider's native request or response body into the
+// Canonical shape. raw is the untouched body bytes - a Normalizer never
+// consumes or mutates the caller's copy.
+type Normalizer interface {
+	Normalize(raw []byte) (Canonical, error)
+}
+
+// registry is the fixed set of built-in Normalizers, keyed by the
+// lowercased Route.Provider value that selects them. Unlike
+// detector.Registry this has no pack/overlay directory to load - the
+// provider set is a closed list of wire formats this gateway speaks, not
+// something an operator extends at runtime.
+var registry = map[string]Normalizer{
+	"openai":    openAINormalizer{},
+	"anthropic": anthropicNormalizer{},
+	"gemini":    geminiNormalizer{},
+	"bedrock":   bedrockNormalizer{},
+}
+
+// Get looks up the Normalizer registered for a gateway Route's Provider
+// field. ok is false for an empty or unrecognized provider, in which
+// case callers should fall back to evaluating the raw body.
+func Get(provider string) (Normalizer, bool) {
+	n, ok := registry[strings.ToLower(provider)]
+	return n, ok
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import "strings"
+
+// ListMode picks whether Hosts is an allowlist (only these hosts pass)
+// or a denylist (these hosts are blocked, everything else passes).
+type ListMode string
+
+const (
+	ListModeAllow ListMode = "allow"
+	ListModeDeny  ListMode = "deny"
+)
+
+// HostPolicy enforces the allow/deny mode for shadow-AI blocking,
+// independent of the detection-only policy.Engine rules.
+type HostPolicy struct {
+	Mode  ListMode
+	Hosts []string
+
+	// BlockResponse is returned verbatim as the response body when a
+	// request is blocked; defaults to a minimal JSON error if empty.
+	BlockResponse []byte
+	BlockStatus   int
+}
+
+// Allowed reports whether a request to host may proceed.
+func (p *HostPolicy) Allowed(host string) bool {
+	if p == nil || len(p.Hosts) == 0 {
+		return true
+	}
+	matched := false
+	for _, h := range p.Hosts {
+		if strings.EqualFold(h, host) || strings.HasSuffix(host, "."+h) {
+			matched = true
+			break
+		}
+	}
+	if p.Mode == ListModeAllow {
+		return matched
+	}
+	return !matched
+}
+
+func (p *HostPolicy) blockResponse() ([]byte, int) {
+	status := p.BlockStatus
+	if status == 0 {
+		status = 403
+	}
+	if len(p.BlockResponse) > 0 {
+		return p.BlockResponse, status
+	}
+	return []byte(`{"error":"blocked by senseai shadow AI policy"}`), status
+}
@@ -0,0 +1,151 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// mitmCA issues short-lived leaf certificates on the fly so the proxy
+// can terminate TLS for a CONNECT tunnel and still present a chain the
+// client trusts, as long as mitmCA's root is installed there. It mirrors
+// internal/server's agentCA (self-contained, ECDSA P256), but signs
+// server leafs keyed by hostname instead of client leafs keyed by agent
+// id.
+type mitmCA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+
+	mu    sync.Mutex
+	leafs map[string]*tls.Certificate
+}
+
+func loadOrCreateMITMCA(certPath, keyPath string) (*mitmCA, error) {
+	certPEM, certErr := os.ReadFile(certPath)
+	keyPEM, keyErr := os.ReadFile(keyPath)
+	if certErr == nil && keyErr == nil {
+		return parseMITMCA(certPEM, keyPEM)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate proxy CA key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "SenseAI Proxy CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to self-sign proxy CA: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return nil, fmt.Errorf("failed to persist proxy CA cert: %w", err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return nil, fmt.Errorf("failed to persist proxy CA key: %w", err)
+	}
+
+	return parseMITMCA(certPEM, keyPEM)
+}
+
+func parseMITMCA(certPEM, keyPEM []byte) (*mitmCA, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("invalid proxy CA certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy CA certificate: %w", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("invalid proxy CA key PEM")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy CA key: %w", err)
+	}
+
+	return &mitmCA{cert: cert, certPEM: certPEM, key: key, leafs: make(map[string]*tls.Certificate)}, nil
+}
+
+// leafFor returns a leaf certificate for host, signed by the proxy CA and
+// valid for seven days. Leafs are cached per-host so a busy host isn't
+// re-signed on every request; an expired cached leaf is issued afresh.
+func (ca *mitmCA) leafFor(host string) (*tls.Certificate, error) {
+	ca.mu.Lock()
+	if leaf, ok := ca.leafs[host]; ok && time.Now().Before(leaf.Leaf.NotAfter) {
+		ca.mu.Unlock()
+		return leaf, nil
+	}
+	ca.mu.Unlock()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     time.Now().AddDate(0, 0, 7),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue leaf certificate for %s: %w", host, err)
+	}
+
+	parsed, err := x509.ParseCertificate(derBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse leaf certificate for %s: %w", host, err)
+	}
+
+	leaf := &tls.Certificate{
+		Certificate: [][]byte{derBytes, ca.cert.Raw},
+		PrivateKey:  key,
+		Leaf:        parsed,
+	}
+
+	ca.mu.Lock()
+	ca.leafs[host] = leaf
+	ca.mu.Unlock()
+
+	return leaf, nil
+}
@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"encoding/json"
+
+	"senseai/internal/redact"
+)
+
+// aiRequestBody is the handful of fields worth pulling out of a
+// decrypted request body, across the OpenAI/Anthropic/Gemini-style
+// "messages" shape that most AI API request bodies share closely enough
+// to parse with one loose struct.
+type aiRequestBody struct {
+	Model       string          `json:"model"`
+	Stream      bool            `json:"stream"`
+	Temperature *float64        `json:"temperature"`
+	MaxTokens   *int            `json:"max_tokens"`
+	Messages    []aiMessageBody `json:"messages"`
+}
+
+type aiMessageBody struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// messageSummary is what actually gets persisted in Finding.Source - the
+// role and a length, never the message content itself.
+type messageSummary struct {
+	Role          string `json:"role"`
+	ContentLength int    `json:"content_length"`
+}
+
+// requestSummary is the structured record of an intercepted AI API
+// request, built from parseAIRequestBody and attached to Finding.Source.
+type requestSummary struct {
+	Model          string           `json:"model,omitempty"`
+	Stream         bool             `json:"stream,omitempty"`
+	Temperature    *float64         `json:"temperature,omitempty"`
+	MaxTokens      *int             `json:"max_tokens,omitempty"`
+	Messages       []messageSummary `json:"messages,omitempty"`
+	InputTokenSize int              `json:"input_token_size_estimate,omitempty"`
+}
+
+// parseAIRequestBody extracts structured, non-sensitive fields from a
+// JSON request body. It returns ok=false for bodies that aren't JSON at
+// all (plenty of non-chat AI API calls aren't), in which case callers
+// should skip structured inspection rather than fail the request.
+func parseAIRequestBody(body []byte) (requestSummary, bool) {
+	var parsed aiRequestBody
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return requestSummary{}, false
+	}
+
+	summary := requestSummary{
+		Model:       parsed.Model,
+		Stream:      parsed.Stream,
+		Temperature: parsed.Temperature,
+		MaxTokens:   parsed.MaxTokens,
+	}
+
+	var totalContent string
+	for _, m := range parsed.Messages {
+		summary.Messages = append(summary.Messages, messageSummary{
+			Role:          m.Role,
+			ContentLength: len(m.Content),
+		})
+		totalContent += m.Content
+	}
+	summary.InputTokenSize = redact.EstimateTokens(totalContent)
+
+	return summary, true
+}
@@ -0,0 +1,22 @@
+package proxy
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics are registered on the default registerer at package init so
+// they show up on whatever /metrics endpoint the binary eventually
+// exposes, without the proxy package needing to know about one itself.
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "senseai_proxy_requests_total",
+		Help: "Total requests seen by the forward proxy, by upstream host and model.",
+	}, []string{"host", "model"})
+
+	blockedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "senseai_proxy_blocked_total",
+		Help: "Total requests blocked by the forward proxy's allow/deny list, by upstream host.",
+	}, []string{"host"})
+)
+
+func init() {
+	prometheus.MustRegister(requestsTotal, blockedTotal)
+}
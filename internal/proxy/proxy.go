@@ -0,0 +1,264 @@
+// Package proxy implements an explicit forward proxy ("sense proxy")
+// that terminates TLS for CONNECT tunnels using an on-the-fly signed CA,
+// so traffic that passive sniffing can only see the SNI of becomes
+// fully decrypted requests the rest of the detection pipeline can
+// inspect.
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"senseai/internal/detector"
+	"senseai/internal/policy"
+	"senseai/internal/redact"
+)
+
+// Server is the forward proxy. It shares the same finding-handler
+// signature as the passive detectors so findings it produces flow
+// through the same storage/remediation path.
+type Server struct {
+	Addr string
+
+	ca           *mitmCA
+	engine       *policy.Engine
+	redactPolicy *redact.Policy
+	hostPolicy   *HostPolicy
+	handler      func(string, string, string, float64, *policy.Rule)
+
+	client *http.Client
+}
+
+// NewServer builds a proxy Server. certPath/keyPath point at the proxy's
+// MITM CA, created on first run if absent (see mitmCA).
+func NewServer(addr, certPath, keyPath string, engine *policy.Engine, hostPolicy *HostPolicy, handler func(string, string, string, float64, *policy.Rule)) (*Server, error) {
+	ca, err := loadOrCreateMITMCA(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize proxy CA: %w", err)
+	}
+
+	return &Server{
+		Addr:       addr,
+		ca:         ca,
+		engine:     engine,
+		hostPolicy: hostPolicy,
+		handler:    handler,
+		client:     &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// SetRedactPolicy wires the DLP redaction policy in, same as
+// detector.NetworkDetector.SetRedactPolicy.
+func (s *Server) SetRedactPolicy(p *redact.Policy) {
+	s.redactPolicy = p
+}
+
+// Start runs the proxy's HTTP/CONNECT listener until the process exits
+// or the listener errors.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	srv := &http.Server{Handler: http.HandlerFunc(s.serveHTTP)}
+	return srv.Serve(listener)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		s.handleConnect(w, r)
+		return
+	}
+	s.forward(w, r)
+}
+
+// handleConnect terminates TLS for a CONNECT tunnel using an on-the-fly
+// leaf certificate for the tunneled host, then serves any number of
+// decrypted requests off the resulting connection.
+func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Hostname()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "proxy does not support hijacking", http.StatusInternalServerError)
+		return
+	}
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, "failed to hijack connection", http.StatusInternalServerError)
+		return
+	}
+	defer clientConn.Close()
+
+	if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		return
+	}
+
+	leaf, err := s.ca.leafFor(host)
+	if err != nil {
+		return
+	}
+
+	tlsConn := tls.Server(clientConn, &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return leaf, nil
+		},
+	})
+	defer tlsConn.Close()
+	if err := tlsConn.Handshake(); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = host
+		req.RequestURI = ""
+
+		resp, blocked := s.inspectAndForward(req, host)
+		if resp == nil {
+			return
+		}
+		if err := resp.Write(tlsConn); err != nil {
+			return
+		}
+		resp.Body.Close()
+		if blocked {
+			return
+		}
+	}
+}
+
+// forward handles plain-HTTP (non-CONNECT) proxying.
+func (s *Server) forward(w http.ResponseWriter, r *http.Request) {
+	resp, _ := s.inspectAndForward(r, r.URL.Hostname())
+	if resp == nil {
+		http.Error(w, "upstream request failed", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for k, vv := range resp.Header {
+		for _, v := range vv {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// inspectAndForward reads the request body, evaluates the host policy
+// and detection pipeline against it, then either returns a synthetic
+// blocked response or forwards the request upstream and returns its
+// response. The returned *http.Response always has an unread Body the
+// caller is responsible for closing.
+func (s *Server) inspectAndForward(req *http.Request, host string) (*http.Response, bool) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	isAI := detector.IsAIEndpoint(host)
+	var summary requestSummary
+	var hasSummary bool
+	if isAI && len(body) > 0 {
+		summary, hasSummary = parseAIRequestBody(body)
+	}
+
+	model := ""
+	if hasSummary {
+		model = summary.Model
+	}
+	requestsTotal.WithLabelValues(host, model).Inc()
+
+	if !s.hostPolicy.Allowed(host) {
+		blockedTotal.WithLabelValues(host).Inc()
+		s.recordFinding(host, model, summary, hasSummary, body, true)
+		respBody, status := s.hostPolicy.blockResponse()
+		return syntheticResponse(status, respBody), true
+	}
+
+	if isAI {
+		s.recordFinding(host, model, summary, hasSummary, body, false)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	return resp, false
+}
+
+func (s *Server) recordFinding(host, model string, summary requestSummary, hasSummary bool, body []byte, blocked bool) {
+	if s.handler == nil {
+		return
+	}
+
+	meta := map[string]interface{}{
+		"host":    host,
+		"blocked": blocked,
+	}
+	if hasSummary {
+		meta["model"] = summary.Model
+		meta["stream"] = summary.Stream
+		meta["temperature"] = summary.Temperature
+		meta["max_tokens"] = summary.MaxTokens
+		meta["messages"] = summary.Messages
+		meta["input_token_size_estimate"] = summary.InputTokenSize
+	} else if len(body) > 0 {
+		preview := strings.ToLower(string(body))
+		if len(preview) > 100 {
+			preview = preview[:100]
+		}
+		redacted, dlpSummary := redact.Redact(preview, s.redactPolicy)
+		meta["body_preview"] = redacted
+		meta["dlp_secrets_found"] = dlpSummary.SecretsFound
+		meta["dlp_pii_types"] = dlpSummary.PIITypes
+	}
+
+	sourceJSON, _ := json.Marshal(meta)
+
+	var rule *policy.Rule
+	severity := 0.85
+	if s.engine != nil {
+		if matched := s.engine.Evaluate("proxy", host); matched != nil {
+			rule = matched
+			severity = matched.Severity
+			meta["matched_rule"] = matched.Name
+			sourceJSON, _ = json.Marshal(meta)
+		}
+	}
+
+	details := fmt.Sprintf("Proxied AI API request: %s", host)
+	if blocked {
+		details = fmt.Sprintf("Blocked proxied request to %s by host policy", host)
+	}
+	s.handler("proxy", details, string(sourceJSON), severity, rule)
+}
+
+func syntheticResponse(status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    &http.Request{},
+	}
+}
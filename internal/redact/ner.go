@@ -0,0 +1,99 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NERSidecar calls an optional transformers-based NER service for PII
+// types regex alone misses (names, addresses, etc.). It's entirely
+// opt-in: a zero-value NERSidecar (empty URL) is skipped by
+// RedactWithNER.
+type NERSidecar struct {
+	URL     string
+	Timeout time.Duration
+}
+
+type nerRequest struct {
+	Text string `json:"text"`
+}
+
+type nerEntity struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type nerResponse struct {
+	Entities []nerEntity `json:"entities"`
+}
+
+// Detect posts text to the sidecar and returns any entities it finds as
+// PIIMatch values, so callers can fold them into the same redaction
+// pipeline as the regex-based detectors.
+func (n NERSidecar) Detect(text string) ([]PIIMatch, error) {
+	if n.URL == "" {
+		return nil, nil
+	}
+
+	timeout := n.Timeout
+	if timeout == 0 {
+		timeout = 3 * time.Second
+	}
+
+	body, err := json.Marshal(nerRequest{Text: text})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("NER sidecar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("NER sidecar returned status %d", resp.StatusCode)
+	}
+
+	var parsed nerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode NER sidecar response: %w", err)
+	}
+
+	matches := make([]PIIMatch, 0, len(parsed.Entities))
+	for _, e := range parsed.Entities {
+		matches = append(matches, PIIMatch{Type: e.Type, Value: e.Text})
+	}
+	return matches, nil
+}
+
+// RedactWithNER is Redact plus an optional NER pass, folding any
+// sidecar-found entities into the same redaction and summary.
+func RedactWithNER(text string, policy *Policy, sidecar NERSidecar) (string, Summary) {
+	redacted, summary := Redact(text, policy)
+
+	entities, err := sidecar.Detect(redacted)
+	if err != nil || len(entities) == 0 {
+		return redacted, summary
+	}
+
+	seenTypes := make(map[string]bool)
+	for _, t := range summary.PIITypes {
+		seenTypes[t] = true
+	}
+	for _, e := range entities {
+		if e.Value != "" {
+			redacted = strings.ReplaceAll(redacted, e.Value, "«redacted:"+e.Type+"»")
+		}
+		if !seenTypes[e.Type] {
+			seenTypes[e.Type] = true
+			summary.PIITypes = append(summary.PIITypes, e.Type)
+		}
+	}
+	return redacted, summary
+}
@@ -0,0 +1,61 @@
+package redact
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CategoryPolicy configures a single redaction category. Disabled is
+// honoured by Redact; Severity is only returned by Policy.Severity,
+// which nothing calls, so it does not change any finding's severity.
+type CategoryPolicy struct {
+	Severity float64 `yaml:"severity"`
+	Disabled bool    `yaml:"disabled"`
+}
+
+// Policy is the `--redact-policy` YAML for the DLP subsystem: which
+// categories to check, a per-category severity (see CategoryPolicy;
+// findings do not use it), and whether to keep a hashed
+// fingerprint of the original (pre-redaction) text for cross-finding
+// dedup.
+type Policy struct {
+	Categories      map[string]CategoryPolicy `yaml:"categories"`
+	KeepFingerprint bool                      `yaml:"keep_fingerprint"`
+}
+
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (p *Policy) categoryEnabled(name string) bool {
+	if p == nil {
+		return true
+	}
+	cat, ok := p.Categories[name]
+	if !ok {
+		return true
+	}
+	return !cat.Disabled
+}
+
+// Severity returns the configured severity for a category, falling back
+// to defaultSeverity if the policy doesn't mention it.
+func (p *Policy) Severity(category string, defaultSeverity float64) float64 {
+	if p == nil {
+		return defaultSeverity
+	}
+	cat, ok := p.Categories[category]
+	if !ok {
+		return defaultSeverity
+	}
+	return cat.Severity
+}
@@ -0,0 +1,212 @@
+// Package redact scrubs secrets and PII out of captured prompt/response
+// text before it's ever written to storage.Finding.Details. The network
+// detector used to store up to 100 raw bytes of HTTP payload directly;
+// that payload can contain API keys and real user PII, so nothing here
+// is allowed to persist unredacted.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// secretPrefixes maps a well-known credential prefix to the service it
+// belongs to, so a redaction can say *what* was found without keeping
+// the value itself.
+var secretPrefixes = map[string]string{
+	"sk-":    "api_key",
+	"hf_":    "huggingface_token",
+	"AKIA":   "aws_access_key",
+	"xoxb-":  "slack_bot_token",
+	"ghp_":   "github_token",
+	"ghs_":   "github_token",
+	"gho_":   "github_token",
+}
+
+// minSecretEntropy is the Shannon entropy (bits/char) above which a
+// token-shaped string (no spaces, mixed alnum, 20+ chars) is treated as
+// a likely secret even without a recognized prefix.
+const minSecretEntropy = 3.5
+
+var (
+	emailRe      = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	phoneRe      = regexp.MustCompile(`\b(?:\+?1[-.\s]?)?\(?\d{3}\)?[-.\s]?\d{3}[-.\s]?\d{4}\b`)
+	ssnRe        = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	creditCardRe = regexp.MustCompile(`\b(?:\d[ -]*?){13,19}\b`)
+	tokenShapeRe = regexp.MustCompile(`[A-Za-z0-9_\-]{20,}`)
+)
+
+// SecretMatch is a single redacted secret occurrence.
+type SecretMatch struct {
+	Category string
+	Value    string
+}
+
+// PIIMatch is a single redacted PII occurrence.
+type PIIMatch struct {
+	Type  string
+	Value string
+}
+
+// Summary is the structured, non-sensitive record of what was redacted,
+// safe to persist in Finding.Source even though Details only holds the
+// redacted text.
+type Summary struct {
+	SecretsFound        int      `json:"secrets_found"`
+	PIITypes            []string `json:"pii_types"`
+	PromptTokenEstimate int      `json:"prompt_token_estimate"`
+	Fingerprint         string   `json:"fingerprint,omitempty"` // sha256 of the original text, only set if policy asks to keep one for dedup
+}
+
+// Redact scans text for secrets and PII, replaces each match with a
+// «redacted:<category>» marker, and returns the redacted text alongside
+// a structured summary. If policy is nil, all built-in categories are
+// checked and no fingerprint is kept.
+func Redact(text string, policy *Policy) (string, Summary) {
+	redacted := text
+	summary := Summary{}
+
+	if policy == nil || policy.categoryEnabled("secrets") {
+		for _, m := range DetectSecrets(redacted) {
+			redacted = strings.ReplaceAll(redacted, m.Value, "«redacted:"+m.Category+"»")
+			summary.SecretsFound++
+		}
+	}
+
+	if policy == nil || policy.categoryEnabled("pii") {
+		seenTypes := make(map[string]bool)
+		for _, m := range DetectPII(redacted) {
+			redacted = strings.ReplaceAll(redacted, m.Value, "«redacted:"+m.Type+"»")
+			if !seenTypes[m.Type] {
+				seenTypes[m.Type] = true
+				summary.PIITypes = append(summary.PIITypes, m.Type)
+			}
+		}
+	}
+
+	summary.PromptTokenEstimate = estimateTokens(text)
+
+	if policy != nil && policy.KeepFingerprint {
+		sum := sha256.Sum256([]byte(text))
+		summary.Fingerprint = hex.EncodeToString(sum[:])
+	}
+
+	return redacted, summary
+}
+
+// DetectSecrets finds known-prefix credentials and high-entropy,
+// token-shaped strings that look like secrets even without a
+// recognized prefix.
+func DetectSecrets(text string) []SecretMatch {
+	var matches []SecretMatch
+
+	for _, candidate := range tokenShapeRe.FindAllString(text, -1) {
+		if category, ok := matchedPrefix(candidate); ok {
+			matches = append(matches, SecretMatch{Category: category, Value: candidate})
+			continue
+		}
+		if shannonEntropy(candidate) >= minSecretEntropy {
+			matches = append(matches, SecretMatch{Category: "high_entropy_token", Value: candidate})
+		}
+	}
+
+	return matches
+}
+
+func matchedPrefix(s string) (string, bool) {
+	for prefix, category := range secretPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// DetectPII finds emails, phone numbers, SSNs, and Luhn-valid credit
+// card numbers.
+func DetectPII(text string) []PIIMatch {
+	var matches []PIIMatch
+
+	for _, m := range emailRe.FindAllString(text, -1) {
+		matches = append(matches, PIIMatch{Type: "email", Value: m})
+	}
+	for _, m := range ssnRe.FindAllString(text, -1) {
+		matches = append(matches, PIIMatch{Type: "ssn", Value: m})
+	}
+	for _, m := range phoneRe.FindAllString(text, -1) {
+		matches = append(matches, PIIMatch{Type: "phone", Value: m})
+	}
+	for _, m := range creditCardRe.FindAllString(text, -1) {
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, m)
+		if len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits) {
+			matches = append(matches, PIIMatch{Type: "credit_card", Value: m})
+		}
+	}
+
+	return matches
+}
+
+// luhnValid implements the standard Luhn checksum used by all major
+// card networks.
+func luhnValid(digits string) bool {
+	sum := 0
+	alternate := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		n, err := strconv.Atoi(string(digits[i]))
+		if err != nil {
+			return false
+		}
+		if alternate {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		alternate = !alternate
+	}
+	return sum%10 == 0
+}
+
+// shannonEntropy computes bits of entropy per character, the standard
+// quick-and-dirty signal for "this looks like a random secret" vs "this
+// is English prose".
+func shannonEntropy(s string) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// estimateTokens gives a cheap, model-agnostic token count estimate
+// (~4 chars/token, the common rule of thumb for English text) so
+// findings carry a rough prompt size without needing a real tokenizer.
+func estimateTokens(s string) int {
+	return len(s) / 4
+}
+
+// EstimateTokens is the exported form of estimateTokens, for callers
+// outside this package (e.g. the forward proxy) that need the same
+// rough size estimate without running a full Redact pass.
+func EstimateTokens(s string) int {
+	return estimateTokens(s)
+}
@@ -0,0 +1,84 @@
+package redact
+
+import "testing"
+
+func TestLuhnValid_RealCardNumberPasses(t *testing.T) {
+	if !luhnValid("4532015112830366") {
+		t.Fatal("expected a valid Visa test number to pass the Luhn check")
+	}
+}
+
+func TestLuhnValid_OffByOneDigitFails(t *testing.T) {
+	if luhnValid("4532015112830367") {
+		t.Fatal("expected a card number with one digit flipped to fail the Luhn check")
+	}
+}
+
+func TestDetectPII_LuhnInvalidDigitRunIsNotFlaggedAsCard(t *testing.T) {
+	// 16 digits, shaped like a card number, but not Luhn-valid - should
+	// not be reported as a credit_card match.
+	matches := DetectPII("my order number is 1234567890123456")
+	for _, m := range matches {
+		if m.Type == "credit_card" {
+			t.Fatalf("expected no credit_card match for a non-Luhn digit run, got %q", m.Value)
+		}
+	}
+}
+
+func TestDetectPII_ValidCreditCardIsFlagged(t *testing.T) {
+	matches := DetectPII("my card number is 4532015112830366, call me back")
+	var found bool
+	for _, m := range matches {
+		if m.Type == "credit_card" && m.Value == "4532015112830366" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a credit_card match for a Luhn-valid number, got %+v", matches)
+	}
+}
+
+func TestShannonEntropy_RepeatedCharHasZeroEntropy(t *testing.T) {
+	if got := shannonEntropy("aaaaaaaaaa"); got != 0 {
+		t.Fatalf("expected zero entropy for a single repeated character, got %v", got)
+	}
+}
+
+func TestShannonEntropy_RandomLookingStringExceedsThreshold(t *testing.T) {
+	got := shannonEntropy("aZ3x9Qm2Lp7Rt1Vb")
+	if got < minSecretEntropy {
+		t.Fatalf("expected a random-looking token to clear minSecretEntropy (%v), got %v", minSecretEntropy, got)
+	}
+}
+
+func TestDetectSecrets_HighEntropyTokenWithoutPrefixIsFlagged(t *testing.T) {
+	matches := DetectSecrets("token: aZ3x9Qm2Lp7Rt1Vb9Yc4Ws")
+	var found bool
+	for _, m := range matches {
+		if m.Category == "high_entropy_token" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a high_entropy_token match, got %+v", matches)
+	}
+}
+
+func TestDetectSecrets_LowEntropyWordIsNotFlagged(t *testing.T) {
+	matches := DetectSecrets("helloworldhelloworld")
+	for _, m := range matches {
+		if m.Category == "high_entropy_token" {
+			t.Fatalf("expected a repetitive low-entropy token not to be flagged as high entropy, got %q", m.Value)
+		}
+	}
+}
+
+func TestDetectSecrets_KnownPrefixWinsOverEntropyCheck(t *testing.T) {
+	matches := DetectSecrets("here is my key sk-abc123def456ghi789jkl")
+	if len(matches) != 1 {
+		t.Fatalf("expected exactly 1 match, got %d: %+v", len(matches), matches)
+	}
+	if matches[0].Category != "api_key" {
+		t.Fatalf("expected the sk- prefix to be categorized as api_key, got %q", matches[0].Category)
+	}
+}
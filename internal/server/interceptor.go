@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// StreamAuthInterceptor rejects StreamFindings calls that didn't present
+// a client certificate verified against the management CA. Enroll is
+// exempt since that's precisely the RPC a not-yet-certified agent uses
+// to get one.
+func StreamAuthInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if info.FullMethod == "/proto.AgentService/StreamFindings" {
+		if err := requireClientCert(ss.Context()); err != nil {
+			return err
+		}
+	}
+	return handler(srv, ss)
+}
+
+func requireClientCert(ctx context.Context) error {
+	if _, err := verifiedPeerCommonName(ctx); err != nil {
+		return fmt.Errorf("StreamFindings requires an mTLS client certificate issued via Enroll: %w", err)
+	}
+	return nil
+}
+
+// verifiedPeerCommonName returns the CommonName of the client
+// certificate that authenticated ctx's connection - the agentID
+// signCSR bound into that certificate at Enroll time (see ca.go). It
+// only trusts tlsInfo.State.VerifiedChains, never PeerCertificates:
+// PeerCertificates is populated with whatever the client presented even
+// when nothing verified it, while VerifiedChains is only non-empty once
+// the handshake has checked the cert against ClientCAs (ServerTLSConfig
+// uses VerifyClientCertIfGiven specifically so that check still runs for
+// an optional cert). Callers use this instead of trusting a client's
+// self-reported agent id.
+func verifiedPeerCommonName(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("missing peer info")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", fmt.Errorf("connection is not using TLS")
+	}
+	if len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", fmt.Errorf("no client certificate verified against the management CA")
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName, nil
+}
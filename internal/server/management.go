@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"senseai/internal/api"
+	pb "senseai/internal/proto"
+	"senseai/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+func getenvDefault(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// bootstrapTokenEnv is read once at startup; a new agent must present this
+// token on Enroll before it is issued an mTLS client certificate. In a
+// real deployment this would be rotated and distributed out-of-band
+// (e.g. via a provisioning script), not hardcoded.
+const bootstrapTokenEnv = "SENSE_BOOTSTRAP_TOKEN"
+
+// ManagementServer is the gRPC-facing counterpart of the sense agent: it
+// handles enrollment (CSR -> signed cert), ingests the StreamFindings
+// bidi stream, persists findings to Postgres, and fans each one out to
+// the REST API's SSE subscribers.
+type ManagementServer struct {
+	pb.UnimplementedAgentServiceServer
+
+	store *PostgresStore
+	api   *api.Server
+	ca    *agentCA
+
+	bootstrapToken string
+
+	mu     sync.RWMutex
+	agents map[string]AgentRecord
+}
+
+// NewManagementServer wires a ManagementServer on top of an existing
+// Postgres connection. It lazily creates (or loads) the management CA
+// used to sign agent enrollment certificates from ./ca.crt and ./ca.key.
+func NewManagementServer(db *sql.DB) *ManagementServer {
+	store, err := NewPostgresStore(db)
+	if err != nil {
+		log.Fatalf("Failed to initialize management store: %v", err)
+	}
+
+	ca, err := loadOrCreateCA("ca.crt", "ca.key")
+	if err != nil {
+		log.Fatalf("Failed to initialize management CA: %v", err)
+	}
+
+	token := getenvDefault(bootstrapTokenEnv, "changeme-bootstrap-token")
+
+	return &ManagementServer{
+		store:          store,
+		api:            api.NewServer(store),
+		ca:             ca,
+		bootstrapToken: token,
+		agents:         make(map[string]AgentRecord),
+	}
+}
+
+// APIServer exposes the REST/SSE server backed by the same Postgres
+// store, so cmd/server can serve it alongside the gRPC listener.
+func (m *ManagementServer) APIServer() *api.Server {
+	return m.api
+}
+
+func (m *ManagementServer) Stop() {}
+
+// Enroll validates the bootstrap token, signs the agent's CSR, and
+// records the agent's metadata so subsequent findings can be attributed
+// to a host even if the stream reconnects under a new TLS session.
+func (m *ManagementServer) Enroll(ctx context.Context, req *pb.EnrollRequest) (*pb.EnrollResponse, error) {
+	if !constantTimeEqual(req.BootstrapToken, m.bootstrapToken) {
+		return nil, fmt.Errorf("invalid bootstrap token")
+	}
+
+	// agentID is always minted here, never taken from client-supplied
+	// metadata - it's what gets baked into the signed cert's CommonName
+	// (see ca.go's signCSR) and is the identity StreamFindings trusts for
+	// the lifetime of that cert, so letting a caller pick it would let
+	// them mint themselves a valid certificate for an existing agent's
+	// identity.
+	agentID := uuid.NewString()
+
+	certPEM, err := m.ca.signCSR(req.CsrPem, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign agent certificate: %w", err)
+	}
+
+	record := AgentRecord{AgentID: agentID}
+	if req.Metadata != nil {
+		record.Hostname = req.Metadata.Hostname
+		record.OS = req.Metadata.Os
+		record.OSVersion = req.Metadata.OsVersion
+		record.Interfaces = strings.Join(req.Metadata.Interfaces, ",")
+	}
+	if err := m.store.UpsertAgent(record); err != nil {
+		log.Printf("[WARN] failed to persist agent record for %s: %v", agentID, err)
+	}
+
+	m.mu.Lock()
+	m.agents[agentID] = record
+	m.mu.Unlock()
+
+	log.Printf("[ENROLL] agent %s (%s) enrolled", agentID, record.Hostname)
+
+	return &pb.EnrollResponse{
+		AgentId: agentID,
+		CertPem: certPEM,
+		CaPem:   m.ca.certPEM,
+	}, nil
+}
+
+// StreamFindings drains the agent's bidi stream, persisting each finding
+// and acknowledging it so the agent can drop it from its local buffer.
+// The agent's identity is taken from its verified client certificate,
+// not from event.Agent (client-supplied, and StreamAuthInterceptor is
+// the only thing standing between an unauthenticated caller and this
+// RPC) - so a caller can never inject or suppress findings under another
+// agent's id by lying about it in the event.
+func (m *ManagementServer) StreamFindings(stream pb.AgentService_StreamFindingsServer) error {
+	agentID, err := verifiedPeerCommonName(stream.Context())
+	if err != nil {
+		return fmt.Errorf("StreamFindings requires an mTLS client certificate issued via Enroll: %w", err)
+	}
+	m.touchAgent(agentID)
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		ts, parseErr := time.Parse(time.RFC3339, event.Timestamp)
+		if parseErr != nil {
+			ts = time.Now()
+		}
+
+		f := storage.Finding{
+			Type:      event.Type,
+			Details:   event.Details,
+			Source:    event.Source,
+			Timestamp: ts,
+			Severity:  event.Severity,
+		}
+
+		var localID sql.NullInt64
+		if parsed, parseErr := strconv.ParseInt(strings.TrimSpace(event.LocalId), 10, 64); parseErr == nil {
+			localID = sql.NullInt64{Int64: parsed, Valid: true}
+		}
+
+		ack := &pb.Ack{LocalId: event.LocalId, Accepted: true}
+		if err := m.store.LogFinding(agentID, localID, f); err != nil {
+			ack.Accepted = false
+			ack.Error = err.Error()
+			log.Printf("[ERROR] failed to persist finding from %s: %v", agentID, err)
+		} else {
+			m.api.Broadcast(f)
+		}
+
+		if err := stream.Send(ack); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *ManagementServer) touchAgent(agentID string) {
+	m.mu.RLock()
+	record, ok := m.agents[agentID]
+	m.mu.RUnlock()
+	if !ok {
+		record = AgentRecord{AgentID: agentID}
+	}
+	if err := m.store.UpsertAgent(record); err != nil {
+		log.Printf("[WARN] failed to update last_seen for agent %s: %v", agentID, err)
+	}
+}
+
+// constantTimeEqual reports whether got equals want without leaking
+// timing information a caller could use to brute-force the bootstrap
+// token byte by byte. An empty want always fails, even against an empty
+// got, so an unconfigured token denies rather than matching "".
+func constantTimeEqual(got, want string) bool {
+	if want == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"senseai/internal/storage"
+)
+
+// PostgresStore persists findings streamed in from enrolled agents. It
+// mirrors storage.Store's schema but targets Postgres instead of SQLite,
+// since the management server is expected to aggregate findings from an
+// entire fleet rather than a single host.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) (*PostgresStore, error) {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS findings (
+            id SERIAL PRIMARY KEY,
+            agent_id TEXT,
+            type TEXT,
+            details TEXT,
+            source TEXT,
+            timestamp TIMESTAMPTZ,
+            severity DOUBLE PRECISION
+        );
+        -- local_id and its uniqueness constraint are added separately (rather
+        -- than inline above) so a findings table created by an older binary
+        -- picks them up too, instead of CREATE TABLE IF NOT EXISTS silently
+        -- skipping them on an already-existing table.
+        ALTER TABLE findings ADD COLUMN IF NOT EXISTS local_id BIGINT;
+        DO $$
+        BEGIN
+            ALTER TABLE findings ADD CONSTRAINT findings_agent_id_local_id_key UNIQUE (agent_id, local_id);
+        EXCEPTION
+            WHEN duplicate_object THEN NULL;
+        END $$;
+        CREATE TABLE IF NOT EXISTS agents (
+            agent_id TEXT PRIMARY KEY,
+            hostname TEXT,
+            os TEXT,
+            os_version TEXT,
+            interfaces TEXT,
+            enrolled_at TIMESTAMPTZ,
+            last_seen_at TIMESTAMPTZ
+        );
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create management schema: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// LogFinding persists a finding forwarded by an agent. localID is the
+// agent's own outbox row ID (see agent.outbox) - agentID+localID together
+// are this row's idempotency key, so a finding the agent resends because
+// its ack was slow or lost (see Client.streamUntilError) lands once
+// instead of duplicating on every resend. localID.Valid is false when the
+// agent didn't send a usable local_id (e.g. an older client); Postgres
+// never treats two NULLs as conflicting under a UNIQUE constraint, so
+// those findings fall back to always-insert instead of colliding with
+// each other under a shared placeholder id.
+func (s *PostgresStore) LogFinding(agentID string, localID sql.NullInt64, f storage.Finding) error {
+	_, err := s.db.Exec(
+		"INSERT INTO findings (agent_id, local_id, type, details, source, timestamp, severity) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (agent_id, local_id) DO NOTHING",
+		agentID, localID, f.Type, f.Details, f.Source, f.Timestamp, f.Severity,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to log finding from agent %s: %w", agentID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) GetFindings() ([]storage.Finding, error) {
+	rows, err := s.db.Query("SELECT id, type, details, source, timestamp, severity FROM findings ORDER BY id DESC LIMIT 1000")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []storage.Finding
+	for rows.Next() {
+		var f storage.Finding
+		if err := rows.Scan(&f.ID, &f.Type, &f.Details, &f.Source, &f.Timestamp, &f.Severity); err != nil {
+			continue
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+func (s *PostgresStore) UpsertAgent(meta AgentRecord) error {
+	_, err := s.db.Exec(`
+        INSERT INTO agents (agent_id, hostname, os, os_version, interfaces, enrolled_at, last_seen_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $6)
+        ON CONFLICT (agent_id) DO UPDATE SET last_seen_at = $6
+    `, meta.AgentID, meta.Hostname, meta.OS, meta.OSVersion, meta.Interfaces, time.Now())
+	return err
+}
+
+// AgentRecord is the persisted view of AgentMetadata tagged on every
+// finding forwarded by that agent.
+type AgentRecord struct {
+	AgentID    string
+	Hostname   string
+	OS         string
+	OSVersion  string
+	Interfaces string
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ServerTLSConfig builds the TLS configuration for the gRPC listener.
+// Client certificates are requested but not required at the transport
+// level (tls.VerifyClientCertIfGiven, not RequireAndVerifyClientCert)
+// because a brand new agent has no cert yet when it calls Enroll - but
+// VerifyClientCertIfGiven still verifies any cert a client does present
+// against ClientCAs, so a hand-rolled self-signed cert fails the TLS
+// handshake outright rather than being silently accepted.
+// StreamAuthInterceptor enforces that StreamFindings specifically cannot
+// proceed without a verified chain, which is where mTLS actually needs
+// to be authoritative.
+func (ca *agentCA) ServerTLSConfig() (*tls.Config, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "senseai-management"},
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost", "senseai-management"},
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &ca.key.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue management server certificate: %w", err)
+	}
+
+	serverCert := tls.Certificate{
+		Certificate: [][]byte{derBytes, ca.cert.Raw},
+		PrivateKey:  ca.key,
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// CA exposes the management CA so cmd/server can build TLS credentials
+// for the gRPC listener without reaching into ManagementServer internals.
+func (m *ManagementServer) CA() *agentCA {
+	return m.ca
+}
@@ -18,6 +18,15 @@ type Finding struct {
 	Severity  float64   `json:"severity"`
 }
 
+// BlockedIP records an IP blocked by action.Remediator so the block
+// survives a restart: firewall rules themselves don't persist across a
+// reboot the way this row does, letting the Remediator reapply them.
+type BlockedIP struct {
+	IP        string    `json:"ip"`
+	Backend   string    `json:"backend"` // e.g. "iptables", "nftables", "pfctl"
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
 type Store struct {
 	db *sql.DB
 }
@@ -42,6 +51,17 @@ func NewStore(dbPath string) (*Store, error) {
 		return nil, err
 	}
 
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS blocked_ips (
+            ip TEXT PRIMARY KEY,
+            backend TEXT,
+            blocked_at TEXT
+        );
+    `)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Store{db: db}, nil
 }
 
@@ -88,3 +108,45 @@ func (s *Store) GetFindings() ([]Finding, error) {
 	}
 	return list, nil
 }
+
+// AddBlockedIP records ip as blocked by backend, or refreshes the record
+// if it was already blocked (e.g. a policy re-matched after Unblock).
+func (s *Store) AddBlockedIP(ip, backend string) error {
+	_, err := s.db.Exec(
+		"INSERT INTO blocked_ips (ip, backend, blocked_at) VALUES (?, ?, ?) "+
+			"ON CONFLICT(ip) DO UPDATE SET backend = excluded.backend, blocked_at = excluded.blocked_at",
+		ip, backend, time.Now().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to persist blocked IP %s: %w", ip, err)
+	}
+	return nil
+}
+
+func (s *Store) RemoveBlockedIP(ip string) error {
+	_, err := s.db.Exec("DELETE FROM blocked_ips WHERE ip = ?", ip)
+	if err != nil {
+		return fmt.Errorf("failed to remove blocked IP %s: %w", ip, err)
+	}
+	return nil
+}
+
+func (s *Store) ListBlockedIPs() ([]BlockedIP, error) {
+	rows, err := s.db.Query("SELECT ip, backend, blocked_at FROM blocked_ips ORDER BY blocked_at DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []BlockedIP
+	for rows.Next() {
+		var b BlockedIP
+		var ts string
+		if err := rows.Scan(&b.IP, &b.Backend, &ts); err != nil {
+			continue
+		}
+		b.BlockedAt, _ = time.Parse(time.RFC3339, ts)
+		list = append(list, b)
+	}
+	return list, nil
+}
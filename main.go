@@ -205,17 +205,9 @@ func isPrintable(s string) bool {
     return true
 }
 
-// ScanEndpoints scans for AI-related processes and files (placeholder)
-func (s *SENSE) ScanEndpoints() error {
-    fmt.Println("Scanning endpoints...")
-    details := "Detected AI-related process:\n- Process: python3\n- Library: TensorFlow\n- Action: Placeholder detection (simulated AI model execution)"
-    severity := 0.7
-    s.logFinding("endpoint", details, severity)
-    fmt.Println("----------------------------------------")
-    fmt.Println(details, "\n| Severity:", severity)
-    fmt.Println("----------------------------------------")
-    return nil
-}
+// ScanEndpoints scans for AI-related processes; see endpoint_linux.go for
+// the real eBPF-based implementation and endpoint_other.go for the
+// non-Linux fallback.
 
 // calculateSeverity assigns a severity score
 func (s *SENSE) calculateSeverity(payload string) float64 {